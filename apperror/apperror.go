@@ -0,0 +1,95 @@
+package apperror
+
+import (
+	"backer/helper"
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Error is an error meant for the client: the HTTP status to answer with, a
+// machine-readable code and a message that is safe to show. Err is the cause,
+// which is logged but never sent.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	// Details are sent along as data.errors, e.g. the fields that failed validation.
+	Details any
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Code + ": " + e.Message
+	}
+
+	return e.Code + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func New(status int, code string, message string, err error) *Error {
+	return &Error{Status: status, Code: code, Message: message, Err: err}
+}
+
+func BadRequest(code string, message string, err error) *Error {
+	return New(http.StatusBadRequest, code, message, err)
+}
+
+func Unauthorized(code string, message string, err error) *Error {
+	return New(http.StatusUnauthorized, code, message, err)
+}
+
+func Forbidden(code string, message string, err error) *Error {
+	return New(http.StatusForbidden, code, message, err)
+}
+
+func NotFound(code string, message string, err error) *Error {
+	return New(http.StatusNotFound, code, message, err)
+}
+
+func Conflict(code string, message string, err error) *Error {
+	return New(http.StatusConflict, code, message, err)
+}
+
+func Unprocessable(code string, message string, err error) *Error {
+	return New(http.StatusUnprocessableEntity, code, message, err)
+}
+
+// Internal reports a failure that is not the client's doing. The message should
+// say what failed, never why.
+func Internal(message string, err error) *Error {
+	return New(http.StatusInternalServerError, helper.CodeInternalError, message, err)
+}
+
+// InvalidParams rejects path or query parameters that failed to bind.
+func InvalidParams(message string, err error) *Error {
+	appErr := New(http.StatusBadRequest, helper.CodeInvalidParams, message, err)
+	appErr.Details = helper.FormatValidationError(err)
+	return appErr
+}
+
+// InvalidInput rejects a request body that failed to bind or validate.
+func InvalidInput(message string, err error) *Error {
+	appErr := New(http.StatusUnprocessableEntity, helper.CodeInvalidInput, message, err)
+	appErr.Details = helper.FormatValidationError(err)
+	return appErr
+}
+
+// From returns the application error in err's chain or, failing that, an internal
+// error. Server errors caused by the request running out of time become a 504.
+func From(err error) *Error {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		appErr = Internal(helper.MsgInternalServerError, err)
+	}
+
+	if appErr.Status >= http.StatusInternalServerError && errors.Is(err, context.DeadlineExceeded) {
+		return New(http.StatusGatewayTimeout, helper.CodeTimeout, helper.MsgRequestTimeout, err)
+	}
+
+	return appErr
+}
@@ -0,0 +1,50 @@
+package apperror
+
+import (
+	"backer/helper"
+	"backer/logger"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware answers for the last error a handler attached with c.Error. Client
+// errors are sent as they are; anything else is logged and answered with a
+// generic message, so database and provider errors never reach the client.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		appErr := From(err)
+
+		log := logger.FromContext(c.Request.Context()).With("status", appErr.Status, "code", appErr.Code, "error", err)
+		switch {
+		case errors.Is(err, context.Canceled):
+			log.Info("Request cancelled by the client")
+		case appErr.Status >= http.StatusInternalServerError:
+			log.Error("Request failed")
+		default:
+			log.Debug("Request rejected")
+		}
+
+		// A handler that already started its response can't take it back
+		if c.Writer.Written() {
+			return
+		}
+
+		data := gin.H{"code": appErr.Code}
+		if appErr.Details != nil {
+			data["errors"] = appErr.Details
+		}
+
+		response := helper.APIResponse(appErr.Message, appErr.Status, "error", data)
+		c.AbortWithStatusJSON(appErr.Status, response)
+	}
+}
@@ -16,6 +16,8 @@ type Campaign struct {
 	GoalAmount       int
 	CurrentAmount    int
 	Slug             string
+	PaymentProvider  string
+	MinimumDonation  int
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 	CampaignImages   []CampaignImage
@@ -54,6 +54,7 @@ type CampaignDetailFormatter struct {
 	GoalAmount       int                      `json:"goal_amount"`
 	CurrentAmount    int                      `json:"current_amount"`
 	BackerCount      int                      `json:"backer_count"`
+	MinimumDonation  int                      `json:"minimum_donation"`
 	UserID           int                      `json:"user_id"`
 	Slug             string                   `json:"slug"`
 	User             CampaignUserFormatter    `json:"user"`
@@ -80,6 +81,7 @@ func FormatCampaignDetail(campaign Campaign) CampaignDetailFormatter {
 	campaignDetailFormatter.GoalAmount = campaign.GoalAmount
 	campaignDetailFormatter.CurrentAmount = campaign.CurrentAmount
 	campaignDetailFormatter.BackerCount = campaign.BackerCount
+	campaignDetailFormatter.MinimumDonation = campaign.MinimumDonation
 	campaignDetailFormatter.UserID = campaign.UserID
 	campaignDetailFormatter.Slug = campaign.Slug
 	campaignDetailFormatter.ImageURL = ""
@@ -12,6 +12,7 @@ type CreateCampaignInput struct {
 	Description      string `json:"description" binding:"required"`
 	GoalAmount       int    `json:"goal_amount" binding:"required"`
 	Perks            string `json:"perks" binding:"required"`
+	MinimumDonation  int    `json:"minimum_donation" binding:"gte=0"`
 	User             user.User
 }
 
@@ -1,15 +1,19 @@
 package campaign
 
-import "gorm.io/gorm"
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
 
 type Repository interface {
-	FindAll() ([]Campaign, error)
-	FindByUserID(userID int) ([]Campaign, error)
-	FindByID(ID int) (Campaign, error)
-	Save(campaign Campaign) (Campaign, error)
-	Update(campaign Campaign) (Campaign, error)
-	CreateImage(campaignImage CampaignImage) (CampaignImage, error)
-	MarkAllImagesAsNonPrimary(campaignID int) (bool, error)
+	FindAll(ctx context.Context) ([]Campaign, error)
+	FindByUserID(ctx context.Context, userID int) ([]Campaign, error)
+	FindByID(ctx context.Context, ID int) (Campaign, error)
+	Save(ctx context.Context, campaign Campaign) (Campaign, error)
+	Update(ctx context.Context, campaign Campaign) (Campaign, error)
+	CreateImage(ctx context.Context, campaignImage CampaignImage) (CampaignImage, error)
+	MarkAllImagesAsNonPrimary(ctx context.Context, campaignID int) (bool, error)
 }
 
 type repository struct {
@@ -20,10 +24,10 @@ func NewRepository(db *gorm.DB) *repository {
 	return &repository{db}
 }
 
-func (r *repository) FindAll() ([]Campaign, error) {
+func (r *repository) FindAll(ctx context.Context) ([]Campaign, error) {
 	var campaigns []Campaign
 
-	err := r.db.Order("id DESC").Preload("CampaignImages", "campaign_images.is_primary = 1").Find(&campaigns).Error
+	err := r.db.WithContext(ctx).Order("id DESC").Preload("CampaignImages", "campaign_images.is_primary = ?", 1).Find(&campaigns).Error
 	if err != nil {
 		return campaigns, err
 	}
@@ -31,10 +35,10 @@ func (r *repository) FindAll() ([]Campaign, error) {
 	return campaigns, nil
 }
 
-func (r *repository) FindByUserID(userID int) ([]Campaign, error) {
+func (r *repository) FindByUserID(ctx context.Context, userID int) ([]Campaign, error) {
 	var campaigns []Campaign
 
-	err := r.db.Where("user_id = ?", userID).Order("id DESC").Preload("CampaignImages", "campaign_images.is_primary = 1").Find(&campaigns).Error
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Preload("CampaignImages", "campaign_images.is_primary = ?", 1).Find(&campaigns).Error
 	if err != nil {
 		return campaigns, err
 	}
@@ -42,10 +46,10 @@ func (r *repository) FindByUserID(userID int) ([]Campaign, error) {
 	return campaigns, nil
 }
 
-func (r *repository) FindByID(ID int) (Campaign, error) {
+func (r *repository) FindByID(ctx context.Context, ID int) (Campaign, error) {
 	var campaign Campaign
 
-	err := r.db.Preload("User").Preload("CampaignImages").Where("id = ?", ID).Find(&campaign).Error
+	err := r.db.WithContext(ctx).Preload("User").Preload("CampaignImages").Where("id = ?", ID).Find(&campaign).Error
 	if err != nil {
 		return campaign, err
 	}
@@ -53,8 +57,8 @@ func (r *repository) FindByID(ID int) (Campaign, error) {
 	return campaign, nil
 }
 
-func (r *repository) Save(campaign Campaign) (Campaign, error) {
-	err := r.db.Create(&campaign).Error
+func (r *repository) Save(ctx context.Context, campaign Campaign) (Campaign, error) {
+	err := r.db.WithContext(ctx).Create(&campaign).Error
 	if err != nil {
 		return campaign, err
 	}
@@ -62,8 +66,8 @@ func (r *repository) Save(campaign Campaign) (Campaign, error) {
 	return campaign, nil
 }
 
-func (r *repository) Update(campaign Campaign) (Campaign, error) {
-	err := r.db.Save(&campaign).Error
+func (r *repository) Update(ctx context.Context, campaign Campaign) (Campaign, error) {
+	err := r.db.WithContext(ctx).Save(&campaign).Error
 	if err != nil {
 		return campaign, err
 	}
@@ -71,8 +75,8 @@ func (r *repository) Update(campaign Campaign) (Campaign, error) {
 	return campaign, nil
 }
 
-func (r *repository) CreateImage(campaignImage CampaignImage) (CampaignImage, error) {
-	err := r.db.Create(&campaignImage).Error
+func (r *repository) CreateImage(ctx context.Context, campaignImage CampaignImage) (CampaignImage, error) {
+	err := r.db.WithContext(ctx).Create(&campaignImage).Error
 	if err != nil {
 		return campaignImage, err
 	}
@@ -80,8 +84,8 @@ func (r *repository) CreateImage(campaignImage CampaignImage) (CampaignImage, er
 	return campaignImage, nil
 }
 
-func (r *repository) MarkAllImagesAsNonPrimary(campaignID int) (bool, error) {
-	err := r.db.Model(&CampaignImage{}).Where("campaign_id = ?", campaignID).Update("is_primary", false).Error
+func (r *repository) MarkAllImagesAsNonPrimary(ctx context.Context, campaignID int) (bool, error) {
+	err := r.db.WithContext(ctx).Model(&CampaignImage{}).Where("campaign_id = ?", campaignID).Update("is_primary", 0).Error
 	if err != nil {
 		return false, err
 	}
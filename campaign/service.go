@@ -1,25 +1,32 @@
 package campaign
 
 import (
+	"backer/config"
+	"context"
 	"errors"
 	"fmt"
 
 	"github.com/gosimple/slug"
+	"go.opentelemetry.io/otel"
 )
 
 // Custom errors
 var (
 	ErrCampaignNotFound = errors.New("campaign not found")
 	ErrNotAuthorized    = errors.New("not authorized")
+
+	ErrInvalidMinimumDonation = errors.New("minimum donation exceeds the maximum donation amount")
 )
 
+var tracer = otel.Tracer("backer/campaign")
+
 type Service interface {
-	GetCampaigns(userID int) ([]Campaign, error)
-	GetCampaignByID(input GetCampaignDetailInput) (Campaign, error)
-	CreateCampaign(input CreateCampaignInput) (Campaign, error)
-	UpdateCampaign(inputID GetCampaignDetailInput, inputData CreateCampaignInput) (Campaign, error)
-	ValidateCampaignOwnership(campaignID int, userID int) error
-	SaveCampaignImage(input CreateCampaignImageInput, fileLocation string) (CampaignImage, error)
+	GetCampaigns(ctx context.Context, userID int) ([]Campaign, error)
+	GetCampaignByID(ctx context.Context, input GetCampaignDetailInput) (Campaign, error)
+	CreateCampaign(ctx context.Context, input CreateCampaignInput) (Campaign, error)
+	UpdateCampaign(ctx context.Context, inputID GetCampaignDetailInput, inputData CreateCampaignInput) (Campaign, error)
+	ValidateCampaignOwnership(ctx context.Context, campaignID int, userID int) error
+	SaveCampaignImage(ctx context.Context, input CreateCampaignImageInput, fileLocation string) (CampaignImage, error)
 }
 
 type service struct {
@@ -30,9 +37,12 @@ func NewService(repository Repository) *service {
 	return &service{repository}
 }
 
-func (s *service) GetCampaigns(userID int) ([]Campaign, error) {
+func (s *service) GetCampaigns(ctx context.Context, userID int) ([]Campaign, error) {
+	ctx, span := tracer.Start(ctx, "campaign.GetCampaigns")
+	defer span.End()
+
 	if userID != 0 {
-		campaigns, err := s.repository.FindByUserID(userID)
+		campaigns, err := s.repository.FindByUserID(ctx, userID)
 		if err != nil {
 			return campaigns, err
 		}
@@ -40,7 +50,7 @@ func (s *service) GetCampaigns(userID int) ([]Campaign, error) {
 		return campaigns, nil
 	}
 
-	campaigns, err := s.repository.FindAll()
+	campaigns, err := s.repository.FindAll(ctx)
 	if err != nil {
 		return campaigns, err
 	}
@@ -48,8 +58,11 @@ func (s *service) GetCampaigns(userID int) ([]Campaign, error) {
 	return campaigns, nil
 }
 
-func (s *service) GetCampaignByID(input GetCampaignDetailInput) (Campaign, error) {
-	campaign, err := s.repository.FindByID(input.ID)
+func (s *service) GetCampaignByID(ctx context.Context, input GetCampaignDetailInput) (Campaign, error) {
+	ctx, span := tracer.Start(ctx, "campaign.GetCampaignByID")
+	defer span.End()
+
+	campaign, err := s.repository.FindByID(ctx, input.ID)
 
 	if err != nil {
 		return campaign, err
@@ -67,18 +80,26 @@ func (s *service) generateCampaignSlug(name string, userID int) string {
 	return slug.Make(slugCandidate)
 }
 
-func (s *service) CreateCampaign(input CreateCampaignInput) (Campaign, error) {
+func (s *service) CreateCampaign(ctx context.Context, input CreateCampaignInput) (Campaign, error) {
+	ctx, span := tracer.Start(ctx, "campaign.CreateCampaign")
+	defer span.End()
+
 	campaign := Campaign{}
 	campaign.Name = input.Name
 	campaign.ShortDescription = input.ShortDescription
 	campaign.Description = input.Description
 	campaign.GoalAmount = input.GoalAmount
 	campaign.Perks = input.Perks
+	campaign.MinimumDonation = input.MinimumDonation
 	campaign.UserID = input.User.ID
 
+	if campaign.MinimumDonation > config.AppConfig.MaxDonationAmount {
+		return campaign, ErrInvalidMinimumDonation
+	}
+
 	campaign.Slug = s.generateCampaignSlug(input.Name, input.User.ID)
 
-	newCampaign, err := s.repository.Save(campaign)
+	newCampaign, err := s.repository.Save(ctx, campaign)
 	if err != nil {
 		return newCampaign, err
 	}
@@ -86,8 +107,11 @@ func (s *service) CreateCampaign(input CreateCampaignInput) (Campaign, error) {
 	return newCampaign, nil
 }
 
-func (s *service) UpdateCampaign(inputID GetCampaignDetailInput, inputData CreateCampaignInput) (Campaign, error) {
-	campaign, err := s.repository.FindByID(inputID.ID)
+func (s *service) UpdateCampaign(ctx context.Context, inputID GetCampaignDetailInput, inputData CreateCampaignInput) (Campaign, error) {
+	ctx, span := tracer.Start(ctx, "campaign.UpdateCampaign")
+	defer span.End()
+
+	campaign, err := s.repository.FindByID(ctx, inputID.ID)
 	if err != nil {
 		return campaign, err
 	}
@@ -105,8 +129,13 @@ func (s *service) UpdateCampaign(inputID GetCampaignDetailInput, inputData Creat
 	campaign.Description = inputData.Description
 	campaign.GoalAmount = inputData.GoalAmount
 	campaign.Perks = inputData.Perks
+	campaign.MinimumDonation = inputData.MinimumDonation
+
+	if campaign.MinimumDonation > config.AppConfig.MaxDonationAmount {
+		return campaign, ErrInvalidMinimumDonation
+	}
 
-	updatedCampaign, err := s.repository.Update(campaign)
+	updatedCampaign, err := s.repository.Update(ctx, campaign)
 	if err != nil {
 		return updatedCampaign, err
 	}
@@ -114,8 +143,11 @@ func (s *service) UpdateCampaign(inputID GetCampaignDetailInput, inputData Creat
 	return updatedCampaign, nil
 }
 
-func (s *service) ValidateCampaignOwnership(campaignID int, userID int) error {
-	campaign, err := s.repository.FindByID(campaignID)
+func (s *service) ValidateCampaignOwnership(ctx context.Context, campaignID int, userID int) error {
+	ctx, span := tracer.Start(ctx, "campaign.ValidateCampaignOwnership")
+	defer span.End()
+
+	campaign, err := s.repository.FindByID(ctx, campaignID)
 	if err != nil {
 		return err
 	}
@@ -131,12 +163,15 @@ func (s *service) ValidateCampaignOwnership(campaignID int, userID int) error {
 	return nil
 }
 
-func (s *service) SaveCampaignImage(input CreateCampaignImageInput, fileLocation string) (CampaignImage, error) {
+func (s *service) SaveCampaignImage(ctx context.Context, input CreateCampaignImageInput, fileLocation string) (CampaignImage, error) {
+	ctx, span := tracer.Start(ctx, "campaign.SaveCampaignImage")
+	defer span.End()
+
 	isPrimary := 0
 	if input.IsPrimary {
 		isPrimary = 1
 
-		_, err := s.repository.MarkAllImagesAsNonPrimary(input.CampaignID)
+		_, err := s.repository.MarkAllImagesAsNonPrimary(ctx, input.CampaignID)
 		if err != nil {
 			return CampaignImage{}, err
 		}
@@ -147,7 +182,7 @@ func (s *service) SaveCampaignImage(input CreateCampaignImageInput, fileLocation
 	campaignImage.IsPrimary = isPrimary
 	campaignImage.FileName = fileLocation
 
-	newCampaignImage, err := s.repository.CreateImage(campaignImage)
+	newCampaignImage, err := s.repository.CreateImage(ctx, campaignImage)
 	if err != nil {
 		return newCampaignImage, err
 	}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"backer/config"
+	"backer/database"
+	"backer/transaction"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+)
+
+// runCommand executes a CLI subcommand such as `backer reconcile` instead of starting the server.
+func runCommand(name string, args []string, transactionService transaction.Service) {
+	switch name {
+	case "reconcile":
+		runReconcile(args, transactionService)
+	default:
+		fatal("Unknown command, available commands: migrate, reconcile", "command", name)
+	}
+}
+
+// runMigrate handles `backer migrate up|down|status`. It runs before the startup
+// schema check, so it also works against an out-of-date database.
+func runMigrate(args []string, db *gorm.DB) {
+	if len(args) == 0 {
+		fatal("Usage: backer migrate up|down|status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(db)
+		for _, migration := range applied {
+			fmt.Printf("Applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fatal("Migrate up failed", "error", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date")
+		}
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ExitOnError)
+		steps := flags.Int("steps", 1, "number of migrations to revert")
+		flags.Parse(args[1:])
+
+		reverted, err := database.MigrateDown(db, *steps)
+		for _, migration := range reverted {
+			fmt.Printf("Reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fatal("Migrate down failed", "error", err)
+		}
+	case "status":
+		statuses, err := database.GetMigrationStatus(db)
+		if err != nil {
+			fatal("Migrate status failed", "error", err)
+		}
+		printMigrationStatus(statuses)
+	default:
+		fatal("Unknown migrate command, use up, down or status", "command", args[0])
+	}
+}
+
+func printMigrationStatus(statuses []database.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", "-"
+		if status.AppliedAt != nil {
+			state = "applied"
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if status.Unknown {
+			state = "applied (unknown to this build)"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	w.Flush()
+}
+
+func runReconcile(args []string, transactionService transaction.Service) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	staleAfter := flags.Duration("stale-after", config.AppConfig.ReconcileStaleAfter, "only check transactions pending for longer than this")
+	flags.Parse(args)
+
+	// Ctrl-C stops the run between provider calls instead of killing it mid-write
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := transactionService.Reconcile(ctx, *staleAfter)
+	if err != nil {
+		fatal("Reconcile failed", "error", err)
+	}
+
+	printReconcileReport(report)
+}
+
+func printReconcileReport(report transaction.ReconcileReport) {
+	fmt.Printf("Checked: %d, updated: %d, unknown to provider: %d, discrepancies: %d\n",
+		report.Checked, report.Updated, report.MissingAtProvider, len(report.Discrepancies))
+
+	if len(report.Discrepancies) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TRANSACTION\tORDER ID\tKIND\tLOCAL STATUS\tPROVIDER STATUS\tLOCAL AMOUNT\tPROVIDER AMOUNT\tDETAIL")
+	for _, d := range report.Discrepancies {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			d.TransactionID, d.OrderID, d.Kind, d.LocalStatus, d.ProviderStatus, d.LocalAmount, d.ProviderAmount, d.Detail)
+	}
+	w.Flush()
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
-	"log"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,23 +16,203 @@ type Config struct {
 	DBPassword   string
 	DBName       string
 	ImageBaseURL string
+	// AppBaseURL is the public URL of this API, used for links back to the app itself.
+	AppBaseURL string
+	// FrontendURL is the public URL of the web app, used for links in emails.
+	FrontendURL string
+
+	// HTTP server timeouts. ServerRequestTimeout is the deadline for the database
+	// and payment calls of one request. ServerShutdownTimeout bounds how long
+	// in-flight requests and worker jobs get to finish on SIGTERM.
+	ServerReadHeaderTimeout time.Duration
+	ServerReadTimeout       time.Duration
+	ServerWriteTimeout      time.Duration
+	ServerIdleTimeout       time.Duration
+	ServerRequestTimeout    time.Duration
+	ServerShutdownTimeout   time.Duration
+
+	// MetricsToken, when set, must be sent as a bearer token to scrape /metrics.
+	MetricsToken string
+
+	// LogLevel is debug, info, warn or error; LogFormat is json or text.
+	LogLevel  string
+	LogFormat string
+
+	// TracingExporter is none, otlp or stdout; TracingSampleRatio is the share of
+	// new traces that get recorded.
+	TracingExporter    string
+	TracingSampleRatio float64
+
+	// DBDriver selects the database: mysql, postgres or sqlite.
+	DBDriver string
+	// DBPath is the database file when DBDriver is sqlite.
+	DBPath string
+	DBPort string
+	// DBParams are extra driver parameters in query string form.
+	DBParams string
+	// DBTLS is MySQL's tls parameter (true, false, skip-verify, preferred) or
+	// Postgres' sslmode (disable, require, verify-ca, verify-full).
+	DBTLS string
+	// DBTLSCA is a CA certificate file to verify the database server against.
+	DBTLSCA string
+
+	// Connection pool settings
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+	DBConnMaxIdleTime time.Duration
+	// DBConnectTimeout is how long startup keeps retrying to reach the database.
+	DBConnectTimeout time.Duration
+
+	// PaymentProvider is the gateway used for campaigns that don't pick their own.
+	PaymentProvider string
+	// FakePaymentServerKey signs the notifications of the fake provider (PAYMENT_PROVIDER=fake).
+	FakePaymentServerKey string
+
+	// ReconcileInterval is how often the reconciliation worker runs; zero disables it.
+	ReconcileInterval time.Duration
+	// ReconcileStaleAfter is how long a transaction may stay pending before it is reconciled.
+	ReconcileStaleAfter time.Duration
+
+	// PaymentExpiry is how long a backer has to pay before a pending transaction expires.
+	PaymentExpiry time.Duration
+	// ExpirySweepInterval is how often overdue pending transactions are marked as expired.
+	ExpirySweepInterval time.Duration
+	// PledgeBillingInterval is how often due recurring pledges are charged.
+	PledgeBillingInterval time.Duration
+
+	// MinDonationAmount and MaxDonationAmount bound every donation, in rupiah.
+	MinDonationAmount int
+	MaxDonationAmount int
+
+	// AllowedPaymentTypes restricts the payment types accepted in notifications; empty allows any.
+	AllowedPaymentTypes []string
+
+	// PlatformFeePercent is the platform's cut of every donation.
+	PlatformFeePercent float64
+	// GatewayFeePercent and GatewayFeeFixed estimate what the payment gateway keeps per donation.
+	GatewayFeePercent float64
+	GatewayFeeFixed   int
+
+	// MessageBlocklist holds lowercase words that keep a backer's message off the supporters wall.
+	MessageBlocklist []string
+
+	// SMTPHost is the mail server for outgoing emails; when empty emails are only logged.
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+	// SMTPTimeout bounds sending one email, from dialing the server to its reply.
+	SMTPTimeout time.Duration
+
+	// GuestClaimTTL is how long the link to claim a guest donation stays valid.
+	GuestClaimTTL time.Duration
+
+	// Organization details printed on donation receipts
+	OrgName    string
+	OrgAddress string
+	OrgTaxID   string
+	OrgEmail   string
+	// ReceiptDir is where generated receipt PDFs are stored.
+	ReceiptDir string
+	// ReceiptPrefix starts every receipt number, e.g. RCPT-2026-000042.
+	ReceiptPrefix string
 }
 
 var AppConfig Config
 
 func LoadConfig() {
+	dbDriver := getEnv("DB_DRIVER", "mysql")
+
 	AppConfig = Config{
 		ServerPort:   getEnv("SERVER_PORT", "8080"),
-		ServerHost:   getEnv("SERVER_HOST", "localhost"),
+		ServerHost:   getEnv("SERVER_HOST", ""),
 		DBHost:       getEnv("DB_HOST", "localhost"),
 		DBUser:       getEnv("DB_USER", "root"),
 		DBPassword:   getEnv("DB_PASSWORD", ""),
 		DBName:       getEnv("DB_NAME", "backer"),
 		ImageBaseURL: getEnv("IMAGE_BASE_URL", "http://localhost:8080"),
+		AppBaseURL:   getEnv("APP_BASE_URL", "http://localhost:8080"),
+		FrontendURL:  getEnv("FRONTEND_URL", "http://localhost:3000"),
+
+		ServerReadHeaderTimeout: getEnvDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+		ServerReadTimeout:       getEnvDuration("SERVER_READ_TIMEOUT", 30*time.Second),
+		ServerWriteTimeout:      getEnvDuration("SERVER_WRITE_TIMEOUT", 2*time.Minute),
+		ServerIdleTimeout:       getEnvDuration("SERVER_IDLE_TIMEOUT", 2*time.Minute),
+		ServerRequestTimeout:    getEnvDuration("SERVER_REQUEST_TIMEOUT", 15*time.Second),
+		ServerShutdownTimeout:   getEnvDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+
+		MetricsToken: getEnv("METRICS_TOKEN", ""),
+
+		LogLevel:  getEnv("LOG_LEVEL", "info"),
+		LogFormat: getEnv("LOG_FORMAT", "json"),
+
+		TracingExporter:    getEnv("TRACING_EXPORTER", "none"),
+		TracingSampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+
+		DBDriver: dbDriver,
+		DBPath:   getEnv("DB_PATH", "backer.db"),
+		DBPort:   getEnv("DB_PORT", dbDriverDefaults[dbDriver].port),
+		DBParams: getEnv("DB_PARAMS", dbDriverDefaults[dbDriver].params),
+		DBTLS:    getEnv("DB_TLS", dbDriverDefaults[dbDriver].tls),
+		DBTLSCA:  getEnv("DB_TLS_CA", ""),
+
+		DBMaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 10),
+		DBConnMaxLifetime: getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		DBConnMaxIdleTime: getEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+		DBConnectTimeout:  getEnvDuration("DB_CONNECT_TIMEOUT", time.Minute),
+
+		PaymentProvider:      getEnv("PAYMENT_PROVIDER", "midtrans"),
+		FakePaymentServerKey: getEnv("FAKE_PAYMENT_SERVER_KEY", "fake-server-key"),
+
+		ReconcileInterval:   getEnvDuration("RECONCILE_INTERVAL", 15*time.Minute),
+		ReconcileStaleAfter: getEnvDuration("RECONCILE_STALE_AFTER", 30*time.Minute),
+
+		PaymentExpiry:       getEnvDuration("PAYMENT_EXPIRY", 24*time.Hour),
+		ExpirySweepInterval: getEnvDuration("EXPIRY_SWEEP_INTERVAL", 5*time.Minute),
+
+		PledgeBillingInterval: getEnvDuration("PLEDGE_BILLING_INTERVAL", time.Hour),
+
+		MinDonationAmount: getEnvInt("MIN_DONATION_AMOUNT", 10000),
+		MaxDonationAmount: getEnvInt("MAX_DONATION_AMOUNT", 1000000000),
+
+		AllowedPaymentTypes: getEnvList("ALLOWED_PAYMENT_TYPES"),
+
+		PlatformFeePercent: getEnvFloat("PLATFORM_FEE_PERCENT", 5),
+		GatewayFeePercent:  getEnvFloat("GATEWAY_FEE_PERCENT", 0),
+		GatewayFeeFixed:    getEnvInt("GATEWAY_FEE_FIXED", 0),
+
+		MessageBlocklist: getEnvList("MESSAGE_BLOCKLIST"),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		MailFrom:     getEnv("MAIL_FROM", "Backer <no-reply@backer.local>"),
+		SMTPTimeout:  getEnvDuration("SMTP_TIMEOUT", 30*time.Second),
+
+		GuestClaimTTL: getEnvDuration("GUEST_CLAIM_TTL", 30*24*time.Hour),
+
+		OrgName:       getEnv("ORG_NAME", "Backer"),
+		OrgAddress:    getEnv("ORG_ADDRESS", ""),
+		OrgTaxID:      getEnv("ORG_TAX_ID", ""),
+		OrgEmail:      getEnv("ORG_EMAIL", ""),
+		ReceiptDir:    getEnv("RECEIPT_DIR", "receipts"),
+		ReceiptPrefix: getEnv("RECEIPT_PREFIX", "RCPT"),
 	}
+}
 
-	log.Println("Config loaded successfully")
-	log.Printf("Image Base URL: %s\n", AppConfig.ImageBaseURL)
+// dbDriverDefaults holds the connection defaults that differ per database driver.
+var dbDriverDefaults = map[string]struct {
+	port   string
+	params string
+	tls    string
+}{
+	"mysql":    {port: "3306", params: "charset=utf8mb4&parseTime=True&loc=Local", tls: "false"},
+	"postgres": {port: "5432", params: "", tls: "disable"},
+	"sqlite":   {params: "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"},
 }
 
 // getEnv reads environment variable, returns default if not found
@@ -40,3 +223,62 @@ func getEnv(key string, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvDuration reads a duration such as "15m" from the environment, returns default if not found or invalid
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Warn("Invalid duration in environment, using default", "key", key, "value", value, "default", defaultValue.String())
+		return defaultValue
+	}
+	return duration
+}
+
+// getEnvInt reads an integer from the environment, returns default if missing or invalid
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("Invalid number in environment, using default", "key", key, "value", value, "default", defaultValue)
+		return defaultValue
+	}
+	return number
+}
+
+// getEnvFloat reads a decimal number from the environment, returns default if missing or invalid
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		slog.Warn("Invalid number in environment, using default", "key", key, "value", value, "default", defaultValue)
+		return defaultValue
+	}
+	return number
+}
+
+// getEnvList reads a comma separated list from the environment, lowercased and trimmed
+func getEnvList(key string) []string {
+	var values []string
+
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
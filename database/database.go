@@ -0,0 +1,204 @@
+package database
+
+import (
+	"backer/config"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/go-sql-driver/mysql"
+	gormmysql "gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Custom errors
+var (
+	ErrUnknownDriver = errors.New("unknown DB_DRIVER, use mysql, postgres or sqlite")
+)
+
+// Backoff between connection attempts at startup
+const (
+	initialRetryDelay = time.Second
+	maxRetryDelay     = 30 * time.Second
+)
+
+// Open connects to the database described by the config, retrying with backoff
+// until it is reachable or DB_CONNECT_TIMEOUT passes.
+func Open(cfg config.Config) (*gorm.DB, error) {
+	dialector, target, err := dialectorFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(cfg.DBConnectTimeout)
+	delay := initialRetryDelay
+
+	for attempt := 1; ; attempt++ {
+		db, err := connect(dialector, cfg)
+		if err == nil {
+			slog.Info("Connected to database", "target", target)
+			return db, nil
+		}
+
+		if time.Now().Add(delay).After(deadline) {
+			return nil, fmt.Errorf("database %s unreachable after %d attempts: %w", target, attempt, err)
+		}
+
+		slog.Warn("Database not reachable, retrying", "target", target, "attempt", attempt, "error", err, "retry_in", delay.String())
+		time.Sleep(delay)
+
+		delay = delay * 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// dialectorFor picks the gorm driver for DB_DRIVER. The target describes the
+// database in logs without the password.
+func dialectorFor(cfg config.Config) (gorm.Dialector, string, error) {
+	switch cfg.DBDriver {
+	case "mysql":
+		dsn, err := mysqlDSN(cfg)
+		if err != nil {
+			return nil, "", err
+		}
+		return gormmysql.Open(dsn), fmt.Sprintf("mysql %s@%s/%s", cfg.DBUser, net.JoinHostPort(cfg.DBHost, cfg.DBPort), cfg.DBName), nil
+	case "postgres":
+		dsn, err := postgresDSN(cfg)
+		if err != nil {
+			return nil, "", err
+		}
+		return postgres.Open(dsn), fmt.Sprintf("postgres %s@%s/%s", cfg.DBUser, net.JoinHostPort(cfg.DBHost, cfg.DBPort), cfg.DBName), nil
+	case "sqlite":
+		dsn := cfg.DBPath
+		if cfg.DBParams != "" {
+			dsn = dsn + "?" + cfg.DBParams
+		}
+		return sqlite.Open(dsn), "sqlite " + cfg.DBPath, nil
+	default:
+		return nil, "", fmt.Errorf("%w: %q", ErrUnknownDriver, cfg.DBDriver)
+	}
+}
+
+func connect(dialector gorm.Dialector, cfg config.Config) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+
+	// gorm.Open does not always reach the server, make sure it is really there
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// mysqlDSN builds the MySQL DSN from the config. DB_PARAMS are appended as is, so
+// any driver parameter can be set without a new config field.
+func mysqlDSN(cfg config.Config) (string, error) {
+	mysqlConfig := mysql.NewConfig()
+	mysqlConfig.User = cfg.DBUser
+	mysqlConfig.Passwd = cfg.DBPassword
+	mysqlConfig.Net = "tcp"
+	mysqlConfig.Addr = net.JoinHostPort(cfg.DBHost, cfg.DBPort)
+	mysqlConfig.DBName = cfg.DBName
+
+	tlsConfig, err := tlsConfigName(cfg)
+	if err != nil {
+		return "", err
+	}
+	mysqlConfig.TLSConfig = tlsConfig
+
+	dsn := mysqlConfig.FormatDSN()
+
+	if cfg.DBParams != "" {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn = dsn + separator + cfg.DBParams
+	}
+
+	if _, err := mysql.ParseDSN(dsn); err != nil {
+		return "", fmt.Errorf("invalid database settings: %w", err)
+	}
+
+	return dsn, nil
+}
+
+// postgresDSN builds a Postgres connection URL from the config. DB_TLS is the
+// sslmode and DB_TLS_CA the sslrootcert, unless DB_PARAMS already sets them.
+func postgresDSN(cfg config.Config) (string, error) {
+	params, err := url.ParseQuery(cfg.DBParams)
+	if err != nil {
+		return "", fmt.Errorf("invalid DB_PARAMS: %w", err)
+	}
+
+	if !params.Has("sslmode") && cfg.DBTLS != "" {
+		params.Set("sslmode", cfg.DBTLS)
+	}
+	if !params.Has("sslrootcert") && cfg.DBTLSCA != "" {
+		params.Set("sslrootcert", cfg.DBTLSCA)
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.DBUser, cfg.DBPassword),
+		Host:     net.JoinHostPort(cfg.DBHost, cfg.DBPort),
+		Path:     "/" + cfg.DBName,
+		RawQuery: params.Encode(),
+	}
+
+	return dsn.String(), nil
+}
+
+// tlsConfigName returns the driver's TLS setting. With DB_TLS_CA the server
+// certificate is verified against that CA instead of the system ones.
+func tlsConfigName(cfg config.Config) (string, error) {
+	if cfg.DBTLSCA == "" {
+		return cfg.DBTLS, nil
+	}
+
+	caCert, err := os.ReadFile(cfg.DBTLSCA)
+	if err != nil {
+		return "", fmt.Errorf("reading DB_TLS_CA: %w", err)
+	}
+
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caCert) {
+		return "", fmt.Errorf("DB_TLS_CA %s holds no PEM certificate", cfg.DBTLSCA)
+	}
+
+	err = mysql.RegisterTLSConfig("custom", &tls.Config{
+		RootCAs:    rootCAs,
+		ServerName: cfg.DBHost,
+		MinVersion: tls.VersionTLS12,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return "custom", nil
+}
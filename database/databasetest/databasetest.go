@@ -0,0 +1,39 @@
+// Package databasetest gives tests a migrated database without a database server.
+package databasetest
+
+import (
+	"backer/config"
+	"backer/database"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// Open loads the config with DB_DRIVER=sqlite and returns a fully migrated SQLite
+// database in a temporary directory, closed and removed when the test ends. Other
+// settings can be changed with t.Setenv before calling it.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "backer.db"))
+	config.LoadConfig()
+
+	db, err := database.Open(config.AppConfig)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if _, err := database.MigrateUp(db); err != nil {
+		t.Fatalf("migrating database: %v", err)
+	}
+
+	return db
+}
@@ -0,0 +1,314 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Each driver has its own directory under migrations/ with the same versions, so
+// a migration is added once per dialect.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Custom errors
+var (
+	ErrSchemaOutOfDate = errors.New("database schema is out of date, run `backer migrate up`")
+	ErrNothingToRevert = errors.New("no applied migrations to revert")
+)
+
+// Migration is one embedded schema change. Files are named
+// <version>_<name>.up.sql and <version>_<name>.down.sql.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration has been applied. Migrations found in
+// the database but not in this build are listed with Unknown set.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	Unknown   bool
+}
+
+// SchemaMigration is a row of the schema_migrations table.
+type SchemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// Migrations returns the embedded migrations of a dialect ordered by version.
+func Migrations(dialect string) ([]Migration, error) {
+	migrationsDir := path.Join("migrations", dialect)
+
+	files, err := fs.ReadDir(migrationFiles, migrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for %s: %w", dialect, err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, file := range files {
+		version, name, direction, err := parseMigrationFileName(file.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := migrationFiles.ReadFile(path.Join(migrationsDir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration %04d has two names: %s and %s", version, migration.Name, name)
+		}
+
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func parseMigrationFileName(fileName string) (int, string, string, error) {
+	base := strings.TrimSuffix(fileName, ".sql")
+
+	direction := path.Ext(base)
+	if direction != ".up" && direction != ".down" {
+		return 0, "", "", fmt.Errorf("migration %s must end in .up.sql or .down.sql", fileName)
+	}
+	base = strings.TrimSuffix(base, direction)
+
+	versionPart, name, found := strings.Cut(base, "_")
+	version, err := strconv.Atoi(versionPart)
+	if !found || err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("migration %s must be named <version>_<name>", fileName)
+	}
+
+	return version, name, strings.TrimPrefix(direction, "."), nil
+}
+
+// MigrateUp applies every pending migration in order and returns the ones it applied.
+func MigrateUp(db *gorm.DB) ([]Migration, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(db, true)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := runMigration(db, migration.Up, func(tx *gorm.DB) error {
+			return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// MigrateDown reverts the latest steps applied migrations, newest first.
+func MigrateDown(db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(db, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) == 0 {
+		return nil, ErrNothingToRevert
+	}
+
+	done := []Migration{}
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err := runMigration(db, migration.Down, func(tx *gorm.DB) error {
+			return tx.Delete(&SchemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("reverting migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// GetMigrationStatus lists every embedded migration and whether it is applied.
+func GetMigrationStatus(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(db, false)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []MigrationStatus{}
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	// Whatever is left was applied by a newer build
+	for _, row := range applied {
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, MigrationStatus{Version: row.Version, Name: row.Name, AppliedAt: &appliedAt, Unknown: true})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// CheckSchema returns ErrSchemaOutOfDate when an embedded migration has not been
+// applied yet. A database migrated by a newer build passes, so an older build can
+// keep serving during a rolling deploy.
+func CheckSchema(db *gorm.DB) error {
+	statuses, err := GetMigrationStatus(db)
+	if err != nil {
+		return err
+	}
+
+	pending := []string{}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, fmt.Sprintf("%04d_%s", status.Version, status.Name))
+		}
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("%w (pending: %s)", ErrSchemaOutOfDate, strings.Join(pending, ", "))
+	}
+
+	return nil
+}
+
+// appliedMigrations reads schema_migrations by version. With create unset a missing
+// table just means nothing is applied, so status checks never write to the database.
+func appliedMigrations(db *gorm.DB, create bool) (map[int]SchemaMigration, error) {
+	applied := map[int]SchemaMigration{}
+
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		if !create {
+			return applied, nil
+		}
+		if err := db.Migrator().CreateTable(&SchemaMigration{}); err != nil {
+			return nil, err
+		}
+	}
+
+	var rows []SchemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
+
+// runMigration executes the statements of one migration and records it in a
+// transaction. Postgres and SQLite roll back a failed migration, but MySQL commits
+// DDL implicitly, so there it has to be cleaned up by hand; keep each one small.
+func runMigration(db *gorm.DB, script string, record func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range splitStatements(script) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		return record(tx)
+	})
+}
+
+// splitStatements splits a script on semicolons that end a line, since the driver
+// runs one statement per call. Lines starting with -- are comments.
+func splitStatements(script string) []string {
+	statements := []string{}
+	current := []string{}
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current = append(current, line)
+		if strings.HasSuffix(trimmed, ";") {
+			statement := strings.TrimSuffix(strings.TrimSpace(strings.Join(current, "\n")), ";")
+			statements = append(statements, statement)
+			current = []string{}
+		}
+	}
+
+	if len(current) > 0 {
+		statements = append(statements, strings.TrimSpace(strings.Join(current, "\n")))
+	}
+
+	return statements
+}
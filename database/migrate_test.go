@@ -0,0 +1,47 @@
+package database_test
+
+import (
+	"backer/database"
+	"backer/database/databasetest"
+	"testing"
+)
+
+func TestMigrationsRewriteDuplicateTransactionCodes(t *testing.T) {
+	db := databasetest.Open(t)
+
+	migrations, err := database.Migrations("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Back to the original schema, where codes were not unique yet
+	if _, err := database.MigrateDown(db, len(migrations)-1); err != nil {
+		t.Fatalf("MigrateDown: %v", err)
+	}
+
+	codes := []string{"TRX-1700000000-1-2", "TRX-1700000000-1-2", "TRX-1700000001-1-2", "TRX-1700000000-1-2"}
+	for _, code := range codes {
+		if err := db.Exec("INSERT INTO transactions (campaign_id, user_id, amount, status, code) VALUES (2, 1, 50000, 'paid', ?)", code).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := database.MigrateUp(db); err != nil {
+		t.Fatalf("MigrateUp over duplicate codes: %v", err)
+	}
+
+	var stored []string
+	if err := db.Raw("SELECT code FROM transactions ORDER BY id").Scan(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"TRX-1700000000-1-2", "TRX-1700000000-1-2-2", "TRX-1700000001-1-2", "TRX-1700000000-1-2-4"}
+	if len(stored) != len(want) {
+		t.Fatalf("codes = %v, want %v", stored, want)
+	}
+	for i := range want {
+		if stored[i] != want[i] {
+			t.Errorf("code of transaction %d = %q, want %q", i+1, stored[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,60 @@
+-- Schema the app ran on before migrations were introduced. IF NOT EXISTS lets
+-- databases that were created by hand adopt the migrations; everything added
+-- since comes in the following versions.
+
+CREATE TABLE IF NOT EXISTS users (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  name VARCHAR(255) NOT NULL DEFAULT '',
+  occupation VARCHAR(255) NOT NULL DEFAULT '',
+  email VARCHAR(255) NOT NULL DEFAULT '',
+  password_hash VARCHAR(255) NOT NULL DEFAULT '',
+  avatar_file_name VARCHAR(255) NOT NULL DEFAULT '',
+  role VARCHAR(32) NOT NULL DEFAULT 'user',
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_users_email (email)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS campaigns (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  user_id BIGINT NOT NULL,
+  name VARCHAR(255) NOT NULL DEFAULT '',
+  short_description VARCHAR(255) NOT NULL DEFAULT '',
+  description TEXT NOT NULL,
+  perks TEXT NOT NULL,
+  backer_count BIGINT NOT NULL DEFAULT 0,
+  goal_amount BIGINT NOT NULL DEFAULT 0,
+  current_amount BIGINT NOT NULL DEFAULT 0,
+  slug VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  KEY idx_campaigns_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS campaign_images (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  campaign_id BIGINT NOT NULL,
+  file_name VARCHAR(255) NOT NULL DEFAULT '',
+  is_primary TINYINT NOT NULL DEFAULT 0,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  KEY idx_campaign_images_campaign_id (campaign_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS transactions (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  campaign_id BIGINT NOT NULL,
+  user_id BIGINT NOT NULL DEFAULT 0,
+  amount BIGINT NOT NULL DEFAULT 0,
+  status VARCHAR(32) NOT NULL DEFAULT '',
+  code VARCHAR(255) NOT NULL DEFAULT '',
+  payment_url VARCHAR(1024) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  KEY idx_transactions_campaign_id (campaign_id),
+  KEY idx_transactions_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,12 @@
+DROP TABLE refunds;
+
+DROP INDEX idx_transactions_status ON transactions;
+DROP INDEX idx_transactions_code ON transactions;
+
+ALTER TABLE campaigns
+  DROP COLUMN payment_provider;
+
+ALTER TABLE transactions
+  DROP COLUMN provider,
+  DROP COLUMN expires_at,
+  DROP COLUMN refunded_amount;
@@ -0,0 +1,34 @@
+-- Provider registry, expiry of abandoned checkouts, refunds and unique
+-- transaction codes used as the provider's order ID.
+
+ALTER TABLE transactions
+  ADD COLUMN provider VARCHAR(32) NOT NULL DEFAULT '',
+  ADD COLUMN expires_at DATETIME(3) NULL,
+  ADD COLUMN refunded_amount BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE campaigns
+  ADD COLUMN payment_provider VARCHAR(32) NOT NULL DEFAULT '';
+
+-- Older codes were TRX-<unix seconds>-<user>-<campaign>, so two checkouts in the
+-- same second share one. Every copy but the first gets its ID appended.
+UPDATE transactions AS duplicate
+  JOIN transactions AS earlier ON earlier.code = duplicate.code AND earlier.id < duplicate.id
+  SET duplicate.code = CONCAT(duplicate.code, '-', duplicate.id);
+
+CREATE UNIQUE INDEX idx_transactions_code ON transactions (code);
+CREATE INDEX idx_transactions_status ON transactions (status);
+
+CREATE TABLE refunds (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  transaction_id BIGINT NOT NULL,
+  requested_by BIGINT NOT NULL DEFAULT 0,
+  amount BIGINT NOT NULL DEFAULT 0,
+  reason TEXT NOT NULL,
+  note TEXT NOT NULL,
+  status VARCHAR(32) NOT NULL DEFAULT '',
+  refund_key VARCHAR(128) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  KEY idx_refunds_transaction_id (transaction_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,27 @@
+-- pledge_id is 0 for one-off donations, so it has no foreign key.
+
+ALTER TABLE transactions
+  ADD COLUMN pledge_id BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_transactions_pledge_id ON transactions (pledge_id);
+
+CREATE TABLE pledges (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  user_id BIGINT NOT NULL,
+  campaign_id BIGINT NOT NULL,
+  amount BIGINT NOT NULL DEFAULT 0,
+  `interval` VARCHAR(32) NOT NULL DEFAULT '',
+  status VARCHAR(32) NOT NULL DEFAULT '',
+  provider VARCHAR(32) NOT NULL DEFAULT '',
+  card_token VARCHAR(255) NOT NULL DEFAULT '',
+  next_charge_at DATETIME(3) NULL,
+  last_charged_at DATETIME(3) NULL,
+  failed_attempts BIGINT NOT NULL DEFAULT 0,
+  last_failure_reason TEXT NOT NULL,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  KEY idx_pledges_user_id (user_id),
+  KEY idx_pledges_campaign_id (campaign_id),
+  KEY idx_pledges_status_next_charge_at (status, next_charge_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
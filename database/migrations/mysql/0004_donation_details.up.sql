@@ -0,0 +1,18 @@
+-- Perks, anonymous donations with messages, guest checkout and per-campaign
+-- minimum donations. user_id is 0 for guest checkouts.
+
+ALTER TABLE transactions
+  ADD COLUMN perk VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN is_anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN message TEXT NOT NULL,
+  ADD COLUMN message_status VARCHAR(32) NOT NULL DEFAULT '',
+  ADD COLUMN guest_name VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN guest_email VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN claim_token_hash VARCHAR(64) NOT NULL DEFAULT '',
+  ADD COLUMN claim_token_expires_at DATETIME(3) NULL;
+
+ALTER TABLE campaigns
+  ADD COLUMN minimum_donation BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_transactions_guest_email ON transactions (guest_email);
+CREATE INDEX idx_transactions_claim_token_hash ON transactions (claim_token_hash);
@@ -0,0 +1,28 @@
+CREATE TABLE receipts (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  transaction_id BIGINT NOT NULL,
+  number VARCHAR(64) NOT NULL,
+  file_name VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_receipts_transaction_id (transaction_id),
+  UNIQUE KEY idx_receipts_number (number)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE fraud_alerts (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  transaction_id BIGINT NOT NULL,
+  kind VARCHAR(32) NOT NULL DEFAULT '',
+  expected VARCHAR(255) NOT NULL DEFAULT '',
+  received VARCHAR(255) NOT NULL DEFAULT '',
+  status VARCHAR(32) NOT NULL DEFAULT '',
+  resolution VARCHAR(32) NOT NULL DEFAULT '',
+  resolved_by BIGINT NOT NULL DEFAULT 0,
+  resolution_note TEXT NOT NULL,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  KEY idx_fraud_alerts_transaction_id (transaction_id),
+  KEY idx_fraud_alerts_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,55 @@
+-- campaign_id is 0 for entries on platform accounts.
+CREATE TABLE ledger_journals (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  kind VARCHAR(32) NOT NULL DEFAULT '',
+  reference VARCHAR(128) NOT NULL,
+  campaign_id BIGINT NOT NULL DEFAULT 0,
+  description VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_ledger_journals_reference (reference),
+  KEY idx_ledger_journals_campaign_id (campaign_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE ledger_entries (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  journal_id BIGINT NOT NULL,
+  account VARCHAR(64) NOT NULL DEFAULT '',
+  campaign_id BIGINT NOT NULL DEFAULT 0,
+  debit BIGINT NOT NULL DEFAULT 0,
+  credit BIGINT NOT NULL DEFAULT 0,
+  created_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  KEY idx_ledger_entries_journal_id (journal_id),
+  KEY idx_ledger_entries_campaign_id (campaign_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE bank_accounts (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  user_id BIGINT NOT NULL,
+  bank_name VARCHAR(255) NOT NULL DEFAULT '',
+  account_number VARCHAR(64) NOT NULL DEFAULT '',
+  account_holder VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY idx_bank_accounts_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE payouts (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  campaign_id BIGINT NOT NULL,
+  user_id BIGINT NOT NULL,
+  amount BIGINT NOT NULL DEFAULT 0,
+  status VARCHAR(32) NOT NULL DEFAULT '',
+  bank_name VARCHAR(255) NOT NULL DEFAULT '',
+  account_number VARCHAR(64) NOT NULL DEFAULT '',
+  account_holder VARCHAR(255) NOT NULL DEFAULT '',
+  reviewed_by BIGINT NOT NULL DEFAULT 0,
+  note TEXT NOT NULL,
+  created_at DATETIME(3) NULL,
+  updated_at DATETIME(3) NULL,
+  PRIMARY KEY (id),
+  KEY idx_payouts_campaign_id (campaign_id),
+  KEY idx_payouts_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
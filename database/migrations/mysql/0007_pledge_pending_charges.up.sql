@@ -0,0 +1,7 @@
+-- A pledge charge still pending at the provider is kept on the pledge until its
+-- transaction settles. Failure reasons become fixed codes, so raw provider errors
+-- stored before are replaced.
+
+ALTER TABLE pledges ADD COLUMN pending_transaction_id BIGINT NOT NULL DEFAULT 0;
+
+UPDATE pledges SET last_failure_reason = 'charge_failed' WHERE last_failure_reason <> '';
@@ -0,0 +1,6 @@
+-- When a transaction was paid, printed on its receipt. Transactions paid before
+-- get their last update time as the closest known value.
+
+ALTER TABLE transactions ADD COLUMN paid_at DATETIME(3) NULL;
+
+UPDATE transactions SET paid_at = updated_at WHERE status IN ('paid', 'refunded');
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS campaign_images;
+DROP TABLE IF EXISTS campaigns;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,55 @@
+-- Schema the app ran on before migrations were introduced, kept in step with
+-- the MySQL and SQLite migrations.
+
+CREATE TABLE IF NOT EXISTS users (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL DEFAULT '',
+  occupation VARCHAR(255) NOT NULL DEFAULT '',
+  email VARCHAR(255) NOT NULL DEFAULT '',
+  password_hash VARCHAR(255) NOT NULL DEFAULT '',
+  avatar_file_name VARCHAR(255) NOT NULL DEFAULT '',
+  role VARCHAR(32) NOT NULL DEFAULT 'user',
+  created_at TIMESTAMPTZ NULL,
+  updated_at TIMESTAMPTZ NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+
+CREATE TABLE IF NOT EXISTS campaigns (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL,
+  name VARCHAR(255) NOT NULL DEFAULT '',
+  short_description VARCHAR(255) NOT NULL DEFAULT '',
+  description TEXT NOT NULL DEFAULT '',
+  perks TEXT NOT NULL DEFAULT '',
+  backer_count BIGINT NOT NULL DEFAULT 0,
+  goal_amount BIGINT NOT NULL DEFAULT 0,
+  current_amount BIGINT NOT NULL DEFAULT 0,
+  slug VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NULL,
+  updated_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_campaigns_user_id ON campaigns (user_id);
+
+CREATE TABLE IF NOT EXISTS campaign_images (
+  id BIGSERIAL PRIMARY KEY,
+  campaign_id BIGINT NOT NULL,
+  file_name VARCHAR(255) NOT NULL DEFAULT '',
+  is_primary SMALLINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NULL,
+  updated_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_campaign_images_campaign_id ON campaign_images (campaign_id);
+
+CREATE TABLE IF NOT EXISTS transactions (
+  id BIGSERIAL PRIMARY KEY,
+  campaign_id BIGINT NOT NULL,
+  user_id BIGINT NOT NULL DEFAULT 0,
+  amount BIGINT NOT NULL DEFAULT 0,
+  status VARCHAR(32) NOT NULL DEFAULT '',
+  code VARCHAR(255) NOT NULL DEFAULT '',
+  payment_url VARCHAR(1024) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NULL,
+  updated_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_transactions_campaign_id ON transactions (campaign_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions (user_id);
@@ -0,0 +1,6 @@
+DROP TABLE pledges;
+
+DROP INDEX idx_transactions_pledge_id;
+
+ALTER TABLE transactions
+  DROP COLUMN pledge_id;
@@ -0,0 +1,15 @@
+DROP INDEX idx_transactions_claim_token_hash;
+DROP INDEX idx_transactions_guest_email;
+
+ALTER TABLE campaigns
+  DROP COLUMN minimum_donation;
+
+ALTER TABLE transactions
+  DROP COLUMN perk,
+  DROP COLUMN is_anonymous,
+  DROP COLUMN message,
+  DROP COLUMN message_status,
+  DROP COLUMN guest_name,
+  DROP COLUMN guest_email,
+  DROP COLUMN claim_token_hash,
+  DROP COLUMN claim_token_expires_at;
@@ -0,0 +1,26 @@
+CREATE TABLE receipts (
+  id BIGSERIAL PRIMARY KEY,
+  transaction_id BIGINT NOT NULL,
+  number VARCHAR(64) NOT NULL,
+  file_name VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NULL,
+  updated_at TIMESTAMPTZ NULL
+);
+CREATE UNIQUE INDEX idx_receipts_transaction_id ON receipts (transaction_id);
+CREATE UNIQUE INDEX idx_receipts_number ON receipts (number);
+
+CREATE TABLE fraud_alerts (
+  id BIGSERIAL PRIMARY KEY,
+  transaction_id BIGINT NOT NULL,
+  kind VARCHAR(32) NOT NULL DEFAULT '',
+  expected VARCHAR(255) NOT NULL DEFAULT '',
+  received VARCHAR(255) NOT NULL DEFAULT '',
+  status VARCHAR(32) NOT NULL DEFAULT '',
+  resolution VARCHAR(32) NOT NULL DEFAULT '',
+  resolved_by BIGINT NOT NULL DEFAULT 0,
+  resolution_note TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NULL,
+  updated_at TIMESTAMPTZ NULL
+);
+CREATE INDEX idx_fraud_alerts_transaction_id ON fraud_alerts (transaction_id);
+CREATE INDEX idx_fraud_alerts_status ON fraud_alerts (status);
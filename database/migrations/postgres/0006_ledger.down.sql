@@ -0,0 +1,4 @@
+DROP TABLE payouts;
+DROP TABLE bank_accounts;
+DROP TABLE ledger_entries;
+DROP TABLE ledger_journals;
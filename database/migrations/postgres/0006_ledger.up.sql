@@ -0,0 +1,51 @@
+-- campaign_id is 0 for entries on platform accounts.
+CREATE TABLE ledger_journals (
+  id BIGSERIAL PRIMARY KEY,
+  kind VARCHAR(32) NOT NULL DEFAULT '',
+  reference VARCHAR(128) NOT NULL,
+  campaign_id BIGINT NOT NULL DEFAULT 0,
+  description VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NULL
+);
+CREATE UNIQUE INDEX idx_ledger_journals_reference ON ledger_journals (reference);
+CREATE INDEX idx_ledger_journals_campaign_id ON ledger_journals (campaign_id);
+
+CREATE TABLE ledger_entries (
+  id BIGSERIAL PRIMARY KEY,
+  journal_id BIGINT NOT NULL,
+  account VARCHAR(64) NOT NULL DEFAULT '',
+  campaign_id BIGINT NOT NULL DEFAULT 0,
+  debit BIGINT NOT NULL DEFAULT 0,
+  credit BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NULL
+);
+CREATE INDEX idx_ledger_entries_journal_id ON ledger_entries (journal_id);
+CREATE INDEX idx_ledger_entries_campaign_id ON ledger_entries (campaign_id);
+
+CREATE TABLE bank_accounts (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL,
+  bank_name VARCHAR(255) NOT NULL DEFAULT '',
+  account_number VARCHAR(64) NOT NULL DEFAULT '',
+  account_holder VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NULL,
+  updated_at TIMESTAMPTZ NULL
+);
+CREATE UNIQUE INDEX idx_bank_accounts_user_id ON bank_accounts (user_id);
+
+CREATE TABLE payouts (
+  id BIGSERIAL PRIMARY KEY,
+  campaign_id BIGINT NOT NULL,
+  user_id BIGINT NOT NULL,
+  amount BIGINT NOT NULL DEFAULT 0,
+  status VARCHAR(32) NOT NULL DEFAULT '',
+  bank_name VARCHAR(255) NOT NULL DEFAULT '',
+  account_number VARCHAR(64) NOT NULL DEFAULT '',
+  account_holder VARCHAR(255) NOT NULL DEFAULT '',
+  reviewed_by BIGINT NOT NULL DEFAULT 0,
+  note TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NULL,
+  updated_at TIMESTAMPTZ NULL
+);
+CREATE INDEX idx_payouts_campaign_id ON payouts (campaign_id);
+CREATE INDEX idx_payouts_status ON payouts (status);
@@ -0,0 +1 @@
+ALTER TABLE pledges DROP COLUMN pending_transaction_id;
@@ -0,0 +1 @@
+-- Receipts removed for lacking a number are issued again on demand; nothing to restore.
@@ -0,0 +1,10 @@
+DROP TABLE refunds;
+
+DROP INDEX idx_transactions_status;
+DROP INDEX idx_transactions_code;
+
+ALTER TABLE campaigns DROP COLUMN payment_provider;
+
+ALTER TABLE transactions DROP COLUMN provider;
+ALTER TABLE transactions DROP COLUMN expires_at;
+ALTER TABLE transactions DROP COLUMN refunded_amount;
@@ -0,0 +1,30 @@
+-- Provider registry, expiry of abandoned checkouts, refunds and unique
+-- transaction codes used as the provider's order ID.
+
+ALTER TABLE transactions ADD COLUMN provider VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN expires_at DATETIME NULL;
+ALTER TABLE transactions ADD COLUMN refunded_amount BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE campaigns ADD COLUMN payment_provider VARCHAR(32) NOT NULL DEFAULT '';
+
+-- Older codes were TRX-<unix seconds>-<user>-<campaign>, so two checkouts in the
+-- same second share one. Every copy but the first gets its ID appended.
+UPDATE transactions SET code = code || '-' || id
+  WHERE EXISTS (SELECT 1 FROM transactions AS earlier WHERE earlier.code = transactions.code AND earlier.id < transactions.id);
+
+CREATE UNIQUE INDEX idx_transactions_code ON transactions (code);
+CREATE INDEX idx_transactions_status ON transactions (status);
+
+CREATE TABLE refunds (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  transaction_id BIGINT NOT NULL,
+  requested_by BIGINT NOT NULL DEFAULT 0,
+  amount BIGINT NOT NULL DEFAULT 0,
+  reason TEXT NOT NULL DEFAULT '',
+  note TEXT NOT NULL DEFAULT '',
+  status VARCHAR(32) NOT NULL DEFAULT '',
+  refund_key VARCHAR(128) NOT NULL DEFAULT '',
+  created_at DATETIME NULL,
+  updated_at DATETIME NULL
+);
+CREATE INDEX idx_refunds_transaction_id ON refunds (transaction_id);
@@ -0,0 +1,25 @@
+-- pledge_id is 0 for one-off donations, so it has no foreign key.
+
+ALTER TABLE transactions ADD COLUMN pledge_id BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_transactions_pledge_id ON transactions (pledge_id);
+
+CREATE TABLE pledges (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id BIGINT NOT NULL,
+  campaign_id BIGINT NOT NULL,
+  amount BIGINT NOT NULL DEFAULT 0,
+  "interval" VARCHAR(32) NOT NULL DEFAULT '',
+  status VARCHAR(32) NOT NULL DEFAULT '',
+  provider VARCHAR(32) NOT NULL DEFAULT '',
+  card_token VARCHAR(255) NOT NULL DEFAULT '',
+  next_charge_at DATETIME NULL,
+  last_charged_at DATETIME NULL,
+  failed_attempts BIGINT NOT NULL DEFAULT 0,
+  last_failure_reason TEXT NOT NULL DEFAULT '',
+  created_at DATETIME NULL,
+  updated_at DATETIME NULL
+);
+CREATE INDEX idx_pledges_user_id ON pledges (user_id);
+CREATE INDEX idx_pledges_campaign_id ON pledges (campaign_id);
+CREATE INDEX idx_pledges_status_next_charge_at ON pledges (status, next_charge_at);
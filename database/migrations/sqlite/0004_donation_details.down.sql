@@ -0,0 +1,13 @@
+DROP INDEX idx_transactions_claim_token_hash;
+DROP INDEX idx_transactions_guest_email;
+
+ALTER TABLE campaigns DROP COLUMN minimum_donation;
+
+ALTER TABLE transactions DROP COLUMN perk;
+ALTER TABLE transactions DROP COLUMN is_anonymous;
+ALTER TABLE transactions DROP COLUMN message;
+ALTER TABLE transactions DROP COLUMN message_status;
+ALTER TABLE transactions DROP COLUMN guest_name;
+ALTER TABLE transactions DROP COLUMN guest_email;
+ALTER TABLE transactions DROP COLUMN claim_token_hash;
+ALTER TABLE transactions DROP COLUMN claim_token_expires_at;
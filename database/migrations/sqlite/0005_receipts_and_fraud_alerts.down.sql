@@ -0,0 +1,2 @@
+DROP TABLE fraud_alerts;
+DROP TABLE receipts;
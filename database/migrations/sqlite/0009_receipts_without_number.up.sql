@@ -0,0 +1,5 @@
+-- Receipts used to be numbered in a second statement after the insert, so a failed
+-- update could leave one behind without a number. Removing it lets the receipt be
+-- issued again.
+
+DELETE FROM receipts WHERE number = '';
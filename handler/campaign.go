@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"backer/apperror"
 	"backer/campaign"
 	"backer/helper"
 	"backer/user"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -27,10 +29,9 @@ func NewCampaignHandler(service campaign.Service) *campaignHandler {
 func (h *campaignHandler) GetCampaigns(c *gin.Context) {
 	userID, _ := strconv.Atoi(c.Query("user_id"))
 
-	campaigns, err := h.service.GetCampaigns(userID)
+	campaigns, err := h.service.GetCampaigns(c.Request.Context(), userID)
 	if err != nil {
-		response := helper.APIResponse(helper.MsgFailedToGetCampaigns, http.StatusInternalServerError, "error", nil)
-		c.JSON(http.StatusInternalServerError, response)
+		c.Error(apperror.Internal(helper.MsgFailedToGetCampaigns, err))
 		return
 	}
 
@@ -43,17 +44,18 @@ func (h *campaignHandler) GetCampaign(c *gin.Context) {
 
 	err := c.ShouldBindUri(&input)
 	if err != nil {
-		response := helper.APIResponse(helper.MsgInvalidCampaignID, http.StatusBadRequest, "error", nil)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperror.InvalidParams(helper.MsgInvalidCampaignID, err))
 		return
 	}
 
-	campaignDetail, err := h.service.GetCampaignByID(input)
+	campaignDetail, err := h.service.GetCampaignByID(c.Request.Context(), input)
 	if err != nil {
-		errorMessage := gin.H{"errors": err.Error()}
-
-		response := helper.APIResponse(helper.MsgCampaignNotFound, http.StatusNotFound, "error", errorMessage)
-		c.JSON(http.StatusNotFound, response)
+		switch {
+		case errors.Is(err, campaign.ErrCampaignNotFound):
+			c.Error(apperror.NotFound(helper.CodeCampaignNotFound, helper.MsgCampaignNotFound, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToGetCampaign, err))
+		}
 		return
 	}
 
@@ -66,23 +68,21 @@ func (h *campaignHandler) CreateCampaign(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&input)
 	if err != nil {
-		validationErrors := helper.FormatValidationError(err)
-		errorMessage := gin.H{"errors": validationErrors}
-
-		response := helper.APIResponse(helper.MsgInvalidInput, http.StatusUnprocessableEntity, "error", errorMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperror.InvalidInput(helper.MsgInvalidInput, err))
 		return
 	}
 
 	currentUser := c.MustGet("currentUser").(user.User)
 	input.User = currentUser
 
-	newCampaign, err := h.service.CreateCampaign(input)
+	newCampaign, err := h.service.CreateCampaign(c.Request.Context(), input)
 	if err != nil {
-		errorMessage := gin.H{"errors": err.Error()}
-
-		response := helper.APIResponse(helper.MsgFailedToCreateCampaign, http.StatusInternalServerError, "error", errorMessage)
-		c.JSON(http.StatusInternalServerError, response)
+		switch {
+		case errors.Is(err, campaign.ErrInvalidMinimumDonation):
+			c.Error(apperror.Unprocessable(helper.CodeInvalidMinimumDonation, helper.MsgInvalidMinimumDonation, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToCreateCampaign, err))
+		}
 		return
 	}
 
@@ -95,11 +95,7 @@ func (h *campaignHandler) UpdateCampaign(c *gin.Context) {
 
 	err := c.ShouldBindUri(&inputID)
 	if err != nil {
-		validationErrors := helper.FormatValidationError(err)
-		errorMessage := gin.H{"errors": validationErrors}
-
-		response := helper.APIResponse(helper.MsgInvalidCampaignID, http.StatusBadRequest, "error", errorMessage)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperror.InvalidParams(helper.MsgInvalidCampaignID, err))
 		return
 	}
 
@@ -107,35 +103,25 @@ func (h *campaignHandler) UpdateCampaign(c *gin.Context) {
 
 	err = c.ShouldBindJSON(&inputData)
 	if err != nil {
-		validationErrors := helper.FormatValidationError(err)
-		errorMessage := gin.H{"errors": validationErrors}
-
-		response := helper.APIResponse(helper.MsgInvalidInput, http.StatusUnprocessableEntity, "error", errorMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperror.InvalidInput(helper.MsgInvalidInput, err))
 		return
 	}
 
 	currentUser := c.MustGet("currentUser").(user.User)
 	inputData.User = currentUser
 
-	updatedCampaign, err := h.service.UpdateCampaign(inputID, inputData)
+	updatedCampaign, err := h.service.UpdateCampaign(c.Request.Context(), inputID, inputData)
 	if err != nil {
-		errorMessage := gin.H{"errors": err.Error()}
-
-		if strings.Contains(err.Error(), "not found") {
-			response := helper.APIResponse(helper.MsgCampaignNotFound, http.StatusNotFound, "error", errorMessage)
-			c.JSON(http.StatusNotFound, response)
-			return
+		switch {
+		case errors.Is(err, campaign.ErrCampaignNotFound):
+			c.Error(apperror.NotFound(helper.CodeCampaignNotFound, helper.MsgCampaignNotFound, err))
+		case errors.Is(err, campaign.ErrNotAuthorized):
+			c.Error(apperror.Forbidden(helper.CodeNotAuthorized, helper.MsgNotAuthorizedToUpdateCampaign, err))
+		case errors.Is(err, campaign.ErrInvalidMinimumDonation):
+			c.Error(apperror.Unprocessable(helper.CodeInvalidMinimumDonation, helper.MsgInvalidMinimumDonation, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToUpdateCampaign, err))
 		}
-
-		if strings.Contains(err.Error(), "unauthorized") || strings.Contains(err.Error(), "not authorized") {
-			response := helper.APIResponse(helper.MsgNotAuthorizedToUpdateCampaign, http.StatusForbidden, "error", errorMessage)
-			c.JSON(http.StatusForbidden, response)
-			return
-		}
-
-		response := helper.APIResponse(helper.MsgFailedToUpdateCampaign, http.StatusInternalServerError, "error", errorMessage)
-		c.JSON(http.StatusInternalServerError, response)
 		return
 	}
 
@@ -148,11 +134,7 @@ func (h *campaignHandler) UploadImage(c *gin.Context) {
 
 	err := c.ShouldBind(&input)
 	if err != nil {
-		validationErrors := helper.FormatValidationError(err)
-		errorMessage := gin.H{"errors": validationErrors}
-
-		response := helper.APIResponse(helper.MsgInvalidInput, http.StatusUnprocessableEntity, "error", errorMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperror.InvalidInput(helper.MsgInvalidInput, err))
 		return
 	}
 
@@ -190,24 +172,16 @@ func (h *campaignHandler) UploadImage(c *gin.Context) {
 		return
 	}
 
-	err = h.service.ValidateCampaignOwnership(input.CampaignID, userID)
+	err = h.service.ValidateCampaignOwnership(c.Request.Context(), input.CampaignID, userID)
 	if err != nil {
-		errorMessage := gin.H{"errors": err.Error(), "is_uploaded": false}
-
-		if strings.Contains(err.Error(), "not found") {
-			response := helper.APIResponse(helper.MsgCampaignNotFound, http.StatusNotFound, "error", errorMessage)
-			c.JSON(http.StatusNotFound, response)
-			return
+		switch {
+		case errors.Is(err, campaign.ErrCampaignNotFound):
+			c.Error(apperror.NotFound(helper.CodeCampaignNotFound, helper.MsgCampaignNotFound, err))
+		case errors.Is(err, campaign.ErrNotAuthorized):
+			c.Error(apperror.Forbidden(helper.CodeNotAuthorized, helper.MsgNotAuthorizedToUploadImage, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToSaveImageToDatabase, err))
 		}
-
-		if strings.Contains(err.Error(), "not authorized") {
-			response := helper.APIResponse(helper.MsgNotAuthorizedToUploadImage, http.StatusForbidden, "error", errorMessage)
-			c.JSON(http.StatusForbidden, response)
-			return
-		}
-
-		response := helper.APIResponse(helper.MsgFailedToSaveImageToDatabase, http.StatusInternalServerError, "error", errorMessage)
-		c.JSON(http.StatusInternalServerError, response)
 		return
 	}
 
@@ -228,13 +202,11 @@ func (h *campaignHandler) UploadImage(c *gin.Context) {
 		return
 	}
 
-	_, err = h.service.SaveCampaignImage(input, path)
+	_, err = h.service.SaveCampaignImage(c.Request.Context(), input, path)
 	if err != nil {
 		os.Remove(path)
 
-		errorMessage := gin.H{"errors": err.Error(), "is_uploaded": false}
-		response := helper.APIResponse(helper.MsgFailedToSaveImageToDatabase, http.StatusInternalServerError, "error", errorMessage)
-		c.JSON(http.StatusInternalServerError, response)
+		c.Error(apperror.Internal(helper.MsgFailedToSaveImageToDatabase, err))
 		return
 	}
 
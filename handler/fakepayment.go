@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"backer/payment"
+	"html/template"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+var fakeCheckoutPage = template.Must(template.New("checkout").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Fake checkout {{.Order.OrderID}}</title>
+	<style>
+		body { font-family: sans-serif; max-width: 32rem; margin: 4rem auto; }
+		form { display: inline-block; margin-right: .5rem; }
+		button { padding: .5rem 1rem; font-size: 1rem; }
+	</style>
+</head>
+<body>
+	<h1>Fake payment provider</h1>
+	<p>This page stands in for the payment gateway during local development.</p>
+	<dl>
+		<dt>Order</dt><dd>{{.Order.OrderID}}</dd>
+		<dt>Description</dt><dd>{{.Order.Description}}</dd>
+		<dt>Amount</dt><dd>IDR {{.Order.Amount}}</dd>
+		<dt>Status</dt><dd>{{.Order.Status}}</dd>
+	</dl>
+	{{if .Message}}<p><strong>{{.Message}}</strong></p>{{end}}
+	{{if eq .Order.Status "pending"}}
+	<form method="post" action="/dev/checkout/{{.Order.OrderID}}/pay"><button>Pay</button></form>
+	<form method="post" action="/dev/checkout/{{.Order.OrderID}}/fail"><button>Fail</button></form>
+	<form method="post" action="/dev/checkout/{{.Order.OrderID}}/expire"><button>Expire</button></form>
+	{{else}}
+	<p><a href="{{.FinishURL}}">Back to the app</a></p>
+	{{end}}
+</body>
+</html>
+`))
+
+type fakePaymentHandler struct {
+	checkout payment.FakeCheckout
+}
+
+func NewFakePaymentHandler(checkout payment.FakeCheckout) *fakePaymentHandler {
+	return &fakePaymentHandler{checkout}
+}
+
+func (h *fakePaymentHandler) ShowCheckout(c *gin.Context) {
+	h.renderCheckout(c, http.StatusOK, "")
+}
+
+func (h *fakePaymentHandler) CompleteCheckout(c *gin.Context) {
+	err := h.checkout.Complete(c.Request.Context(), c.Param("order_id"), c.Param("outcome"))
+	if err != nil {
+		h.renderCheckout(c, http.StatusBadRequest, "Notification failed: "+err.Error())
+		return
+	}
+
+	h.renderCheckout(c, http.StatusOK, "Notification delivered")
+}
+
+func (h *fakePaymentHandler) renderCheckout(c *gin.Context, status int, message string) {
+	order, ok := h.checkout.GetOrder(c.Param("order_id"))
+	if !ok {
+		c.String(http.StatusNotFound, "Unknown order. Orders of the fake provider only live until the server restarts.")
+		return
+	}
+
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	fakeCheckoutPage.Execute(c.Writer, gin.H{
+		"Order":     order,
+		"Message":   message,
+		"FinishURL": os.Getenv("FRONTEND_URL") + "/fund-success",
+	})
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"backer/health"
+	"backer/helper"
+	"backer/logger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type healthHandler struct {
+	service health.Service
+}
+
+func NewHealthHandler(service health.Service) *healthHandler {
+	return &healthHandler{service}
+}
+
+// Liveness only tells the orchestrator the process is serving requests; it never
+// touches dependencies, so a database outage doesn't get the pod restarted.
+func (h *healthHandler) Liveness(c *gin.Context) {
+	response := helper.APIResponse(helper.MsgServiceAlive, http.StatusOK, "success", gin.H{"status": "ok"})
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *healthHandler) Readiness(c *gin.Context) {
+	ready, results := h.service.Ready()
+	formatter := health.FormatReadiness(ready, results)
+
+	if !ready {
+		for _, result := range results {
+			if result.Error != nil {
+				logger.FromContext(c.Request.Context()).Warn("Readiness check failed", "check", result.Name, "error", result.Error)
+			}
+		}
+
+		response := helper.APIResponse(helper.MsgServiceNotReady, http.StatusServiceUnavailable, "error", formatter)
+		c.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgServiceReady, http.StatusOK, "success", formatter)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *healthHandler) Version(c *gin.Context) {
+	response := helper.APIResponse(helper.MsgBuildInfoRetrievedSuccess, http.StatusOK, "success", health.FormatBuildInfo(h.service.BuildInfo()))
+	c.JSON(http.StatusOK, response)
+}
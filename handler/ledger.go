@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"backer/apperror"
+	"backer/helper"
+	"backer/ledger"
+	"backer/user"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ledgerHandler struct {
+	service ledger.Service
+}
+
+func NewLedgerHandler(service ledger.Service) *ledgerHandler {
+	return &ledgerHandler{service}
+}
+
+func (h *ledgerHandler) GetBankAccount(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(user.User)
+
+	bankAccount, err := h.service.GetBankAccount(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		c.Error(apperror.Internal(helper.MsgFailedToGetBankAccount, err))
+		return
+	}
+
+	if bankAccount.ID == 0 {
+		c.Error(apperror.NotFound(helper.CodeBankAccountNotFound, helper.MsgBankAccountNotFound, nil))
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgBankAccountRetrievedSuccess, http.StatusOK, "success", ledger.FormatBankAccount(bankAccount))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ledgerHandler) SaveBankAccount(c *gin.Context) {
+	var input ledger.SaveBankAccountInput
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.Error(apperror.InvalidInput(helper.MsgInvalidBankAccountInput, err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	bankAccount, err := h.service.SaveBankAccount(c.Request.Context(), input)
+	if err != nil {
+		c.Error(apperror.Internal(helper.MsgFailedToSaveBankAccount, err))
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgBankAccountSavedSuccessfully, http.StatusOK, "success", ledger.FormatBankAccount(bankAccount))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ledgerHandler) GetCampaignBalance(c *gin.Context) {
+	input, ok := bindCampaignLedgerInput(c)
+	if !ok {
+		return
+	}
+
+	balance, err := h.service.GetCampaignBalance(c.Request.Context(), input)
+	if err != nil {
+		c.Error(campaignLedgerError(err, helper.MsgFailedToGetBalance))
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgBalanceRetrievedSuccess, http.StatusOK, "success", ledger.FormatCampaignBalance(balance))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ledgerHandler) GetCampaignPayouts(c *gin.Context) {
+	input, ok := bindCampaignLedgerInput(c)
+	if !ok {
+		return
+	}
+
+	payouts, err := h.service.GetCampaignPayouts(c.Request.Context(), input)
+	if err != nil {
+		c.Error(campaignLedgerError(err, helper.MsgFailedToGetPayouts))
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgPayoutsRetrievedSuccess, http.StatusOK, "success", ledger.FormatPayouts(payouts))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ledgerHandler) RequestPayout(c *gin.Context) {
+	inputID, ok := bindCampaignLedgerInput(c)
+	if !ok {
+		return
+	}
+
+	var inputData ledger.RequestPayoutInput
+
+	err := c.ShouldBindJSON(&inputData)
+	if err != nil {
+		c.Error(apperror.InvalidInput(helper.MsgInvalidPayoutInput, err))
+		return
+	}
+
+	inputData.User = inputID.User
+
+	payout, err := h.service.RequestPayout(c.Request.Context(), inputID, inputData)
+	if err != nil {
+		switch {
+		case errors.Is(err, ledger.ErrBankAccountRequired):
+			c.Error(apperror.Conflict(helper.CodeBankAccountRequired, helper.MsgBankAccountRequired, err))
+		case errors.Is(err, ledger.ErrInsufficientBalance):
+			c.Error(apperror.Unprocessable(helper.CodeInsufficientBalance, helper.MsgInsufficientBalance, err))
+		default:
+			c.Error(campaignLedgerError(err, helper.MsgFailedToRequestPayout))
+		}
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgPayoutRequestedSuccessfully, http.StatusCreated, "success", ledger.FormatPayout(payout))
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *ledgerHandler) GetPayouts(c *gin.Context) {
+	var input ledger.GetPayoutsInput
+
+	err := c.ShouldBindQuery(&input)
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidPayoutInput, err))
+		return
+	}
+
+	payouts, err := h.service.GetPayouts(c.Request.Context(), input)
+	if err != nil {
+		c.Error(apperror.Internal(helper.MsgFailedToGetPayouts, err))
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgPayoutsRetrievedSuccess, http.StatusOK, "success", ledger.FormatPayouts(payouts))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ledgerHandler) ApprovePayout(c *gin.Context) {
+	h.reviewPayout(c, h.service.ApprovePayout)
+}
+
+func (h *ledgerHandler) RejectPayout(c *gin.Context) {
+	h.reviewPayout(c, h.service.RejectPayout)
+}
+
+func (h *ledgerHandler) reviewPayout(c *gin.Context, review func(context.Context, ledger.GetPayoutInput, ledger.ReviewPayoutInput) (ledger.Payout, error)) {
+	var inputID ledger.GetPayoutInput
+
+	err := c.ShouldBindUri(&inputID)
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidPayoutInput, err))
+		return
+	}
+
+	// The note is optional, so an empty body is fine
+	var inputData ledger.ReviewPayoutInput
+	if c.Request.ContentLength > 0 {
+		err = c.ShouldBindJSON(&inputData)
+		if err != nil {
+			c.Error(apperror.InvalidInput(helper.MsgInvalidPayoutInput, err))
+			return
+		}
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	inputData.User = currentUser
+
+	payout, err := review(c.Request.Context(), inputID, inputData)
+	if err != nil {
+		switch {
+		case errors.Is(err, ledger.ErrPayoutNotFound):
+			c.Error(apperror.NotFound(helper.CodePayoutNotFound, helper.MsgPayoutNotFound, err))
+		case errors.Is(err, ledger.ErrPayoutAlreadyReviewed):
+			c.Error(apperror.Conflict(helper.CodePayoutAlreadyReviewed, helper.MsgPayoutAlreadyReviewed, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToReviewPayout, err))
+		}
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgPayoutReviewedSuccessfully, http.StatusOK, "success", ledger.FormatPayout(payout))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ledgerHandler) GetBalanceReport(c *gin.Context) {
+	report, err := h.service.GetBalanceReport(c.Request.Context())
+	if err != nil {
+		c.Error(apperror.Internal(helper.MsgFailedToGetBalanceReport, err))
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgBalanceReportRetrievedSuccess, http.StatusOK, "success", ledger.FormatBalanceReport(report))
+	c.JSON(http.StatusOK, response)
+}
+
+func bindCampaignLedgerInput(c *gin.Context) (ledger.GetCampaignLedgerInput, bool) {
+	var input ledger.GetCampaignLedgerInput
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidCampaignID, err))
+		return input, false
+	}
+
+	input.User = c.MustGet("currentUser").(user.User)
+
+	return input, true
+}
+
+// campaignLedgerError maps the errors shared by the campaign ledger routes.
+func campaignLedgerError(err error, failureMessage string) *apperror.Error {
+	switch {
+	case errors.Is(err, ledger.ErrCampaignNotFound):
+		return apperror.NotFound(helper.CodeCampaignNotFound, helper.MsgCampaignNotFound, err)
+	case errors.Is(err, ledger.ErrNotAuthorized):
+		return apperror.Forbidden(helper.CodeNotAuthorized, helper.MsgNotAuthorizedToViewBalance, err)
+	}
+
+	return apperror.Internal(failureMessage, err)
+}
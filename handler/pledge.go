@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"backer/apperror"
+	"backer/helper"
+	"backer/pledge"
+	"backer/user"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type pledgeHandler struct {
+	service pledge.Service
+}
+
+func NewPledgeHandler(service pledge.Service) *pledgeHandler {
+	return &pledgeHandler{service}
+}
+
+func (h *pledgeHandler) GetPledges(c *gin.Context) {
+	currentUser := c.MustGet("currentUser").(user.User)
+
+	pledges, err := h.service.GetPledgesByUserID(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		c.Error(apperror.Internal(helper.MsgFailedToGetPledges, err))
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgPledgesRetrievedSuccessfully, http.StatusOK, "success", pledge.FormatPledges(pledges))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *pledgeHandler) CreatePledge(c *gin.Context) {
+	var input pledge.CreatePledgeInput
+
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		c.Error(apperror.InvalidInput(helper.MsgInvalidPledgeInput, err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	newPledge, err := h.service.CreatePledge(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, pledge.ErrCampaignNotFound):
+			c.Error(apperror.NotFound(helper.CodeCampaignNotFound, helper.MsgCampaignNotFound, err))
+		case errors.Is(err, pledge.ErrAmountOutOfRange):
+			c.Error(apperror.Unprocessable(helper.CodeAmountOutOfRange, helper.MsgInvalidDonationAmount, err))
+		case errors.Is(err, pledge.ErrFirstChargeFailed):
+			// The failure reason is one of the fixed pledge.Failure* codes, never a provider error
+			appErr := apperror.New(http.StatusPaymentRequired, helper.CodeFirstChargeFailed, helper.MsgPledgeChargeFailed, err)
+			appErr.Details = newPledge.LastFailureReason
+			c.Error(appErr)
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToCreatePledge, err))
+		}
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgPledgeCreatedSuccessfully, http.StatusCreated, "success", pledge.FormatPledge(newPledge))
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *pledgeHandler) PausePledge(c *gin.Context) {
+	h.changePledgeStatus(c, h.service.PausePledge)
+}
+
+func (h *pledgeHandler) ResumePledge(c *gin.Context) {
+	h.changePledgeStatus(c, h.service.ResumePledge)
+}
+
+func (h *pledgeHandler) CancelPledge(c *gin.Context) {
+	h.changePledgeStatus(c, h.service.CancelPledge)
+}
+
+// changePledgeStatus handles the pause, resume and cancel routes, which only differ
+// in the service method they call.
+func (h *pledgeHandler) changePledgeStatus(c *gin.Context, change func(ctx context.Context, input pledge.GetPledgeInput) (pledge.Pledge, error)) {
+	var input pledge.GetPledgeInput
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidPledgeID, err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	updatedPledge, err := change(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, pledge.ErrPledgeNotFound):
+			c.Error(apperror.NotFound(helper.CodePledgeNotFound, helper.MsgPledgeNotFound, err))
+		case errors.Is(err, pledge.ErrNotAuthorized):
+			c.Error(apperror.Forbidden(helper.CodeNotAuthorized, helper.MsgNotAuthorizedToUpdatePledge, err))
+		case errors.Is(err, pledge.ErrInvalidStatusChange):
+			c.Error(apperror.Conflict(helper.CodeInvalidStatusChange, helper.MsgInvalidPledgeStatusChange, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToUpdatePledge, err))
+		}
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgPledgeUpdatedSuccessfully, http.StatusOK, "success", pledge.FormatPledge(updatedPledge))
+	c.JSON(http.StatusOK, response)
+}
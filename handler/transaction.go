@@ -1,14 +1,15 @@
 package handler
 
 import (
+	"backer/apperror"
 	"backer/helper"
+	"backer/logger"
+	"backer/payment"
 	"backer/transaction"
 	"backer/user"
 	"errors"
 	"io"
-	"log"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,35 +27,23 @@ func (h *transactionHandler) GetCampaignTransactions(c *gin.Context) {
 
 	err := c.ShouldBindUri(&input)
 	if err != nil {
-		validationErrors := helper.FormatValidationError(err)
-		errorMessages := gin.H{"errors": validationErrors}
-
-		response := helper.APIResponse(helper.MsgInvalidTransactionInput, http.StatusBadRequest, "error", errorMessages)
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperror.InvalidParams(helper.MsgInvalidTransactionInput, err))
 		return
 	}
 
 	currentUser := c.MustGet("currentUser").(user.User)
 	input.User = currentUser
 
-	transactions, err := h.service.GetTransactionsByCampaignID(input)
+	transactions, err := h.service.GetTransactionsByCampaignID(c.Request.Context(), input)
 	if err != nil {
-		errorMessage := gin.H{"errors": err.Error()}
-
-		if errors.Is(err, transaction.ErrCampaignNotFound) {
-			response := helper.APIResponse(helper.MsgCampaignNotFound, http.StatusNotFound, "error", errorMessage)
-			c.JSON(http.StatusNotFound, response)
-			return
-		}
-
-		if errors.Is(err, transaction.ErrNotAuthorized) {
-			response := helper.APIResponse(helper.MsgNotAuthorizedToViewTransactions, http.StatusForbidden, "error", errorMessage)
-			c.JSON(http.StatusForbidden, response)
-			return
+		switch {
+		case errors.Is(err, transaction.ErrCampaignNotFound):
+			c.Error(apperror.NotFound(helper.CodeCampaignNotFound, helper.MsgCampaignNotFound, err))
+		case errors.Is(err, transaction.ErrNotAuthorized):
+			c.Error(apperror.Forbidden(helper.CodeNotAuthorized, helper.MsgNotAuthorizedToViewTransactions, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToGetCampaignTransactions, err))
 		}
-
-		response := helper.APIResponse(helper.MsgFailedToGetCampaignTransactions, http.StatusInternalServerError, "error", errorMessage)
-		c.JSON(http.StatusInternalServerError, response)
 		return
 	}
 
@@ -66,11 +55,9 @@ func (h *transactionHandler) GetUserTransactions(c *gin.Context) {
 	currentUser := c.MustGet("currentUser").(user.User)
 	userID := currentUser.ID
 
-	transactions, err := h.service.GetTransactionsByUserID(userID)
+	transactions, err := h.service.GetTransactionsByUserID(c.Request.Context(), userID)
 	if err != nil {
-		errorMessage := gin.H{"errors": err.Error()}
-		response := helper.APIResponse(helper.MsgFailedToGetUserTransactions, http.StatusInternalServerError, "error", errorMessage)
-		c.JSON(http.StatusInternalServerError, response)
+		c.Error(apperror.Internal(helper.MsgFailedToGetUserTransactions, err))
 		return
 	}
 
@@ -83,29 +70,25 @@ func (h *transactionHandler) CreateTransaction(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&input)
 	if err != nil {
-		validationErrors := helper.FormatValidationError(err)
-		errorMessages := gin.H{"errors": validationErrors}
-
-		response := helper.APIResponse(helper.MsgInvalidTransactionInput, http.StatusUnprocessableEntity, "error", errorMessages)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperror.InvalidInput(helper.MsgInvalidTransactionInput, err))
 		return
 	}
 
 	currentUser := c.MustGet("currentUser").(user.User)
 	input.User = currentUser
 
-	newTransaction, err := h.service.CreateTransaction(input)
+	newTransaction, err := h.service.CreateTransaction(c.Request.Context(), input)
 	if err != nil {
-		errorMessage := gin.H{"errors": err.Error()}
-
-		if errors.Is(err, transaction.ErrCampaignNotFound) {
-			response := helper.APIResponse(helper.MsgCampaignNotFound, http.StatusNotFound, "error", errorMessage)
-			c.JSON(http.StatusNotFound, response)
-			return
+		switch {
+		case errors.Is(err, transaction.ErrCampaignNotFound):
+			c.Error(apperror.NotFound(helper.CodeCampaignNotFound, helper.MsgCampaignNotFound, err))
+		case errors.Is(err, transaction.ErrAmountOutOfRange):
+			c.Error(apperror.Unprocessable(helper.CodeAmountOutOfRange, helper.MsgInvalidDonationAmount, err))
+		case errors.Is(err, transaction.ErrInvalidPerk):
+			c.Error(apperror.Unprocessable(helper.CodeInvalidPerk, helper.MsgInvalidPerk, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToCreateTransaction, err))
 		}
-
-		response := helper.APIResponse(helper.MsgFailedToCreateTransaction, http.StatusInternalServerError, "error", errorMessage)
-		c.JSON(http.StatusInternalServerError, response)
 		return
 	}
 
@@ -113,48 +96,381 @@ func (h *transactionHandler) CreateTransaction(c *gin.Context) {
 	c.JSON(http.StatusCreated, response)
 }
 
+func (h *transactionHandler) RefundTransaction(c *gin.Context) {
+	var inputID transaction.GetTransactionInput
+
+	err := c.ShouldBindUri(&inputID)
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidTransactionID, err))
+		return
+	}
+
+	var inputData transaction.CreateRefundInput
+
+	err = c.ShouldBindJSON(&inputData)
+	if err != nil {
+		c.Error(apperror.InvalidInput(helper.MsgInvalidRefundInput, err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	inputData.User = currentUser
+
+	refund, err := h.service.RefundTransaction(c.Request.Context(), inputID, inputData)
+	if err != nil {
+		switch {
+		case errors.Is(err, transaction.ErrTransactionNotFound):
+			c.Error(apperror.NotFound(helper.CodeTransactionNotFound, helper.MsgTransactionNotFound, err))
+		case errors.Is(err, transaction.ErrNotAuthorized):
+			c.Error(apperror.Forbidden(helper.CodeNotAuthorized, helper.MsgNotAuthorizedToRefundTransaction, err))
+		case errors.Is(err, transaction.ErrTransactionNotRefundable):
+			c.Error(apperror.Conflict(helper.CodeTransactionNotRefundable, helper.MsgTransactionNotRefundable, err))
+		case errors.Is(err, transaction.ErrRefundAmountExceeded):
+			c.Error(apperror.Unprocessable(helper.CodeRefundAmountExceeded, helper.MsgRefundAmountExceeded, err))
+		case errors.Is(err, payment.ErrRefundRejected):
+			c.Error(apperror.New(http.StatusBadGateway, helper.CodeRefundRejected, helper.MsgRefundRejected, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToRefundTransaction, err))
+		}
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgRefundCreatedSuccessfully, http.StatusCreated, "success", transaction.FormatRefund(refund))
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetNotification receives payment notifications. The provider comes from the
+// route, with the legacy /transactions/notification route meaning the default one.
 func (h *transactionHandler) GetNotification(c *gin.Context) {
-	// Read the raw body first for logging, before binding it to the struct
-	bodyBytes, _ := io.ReadAll(c.Request.Body)
-	log.Println("=== MIDTRANS NOTIFICATION RAW BODY ===")
-	log.Println(string(bodyBytes))
-	log.Println("=== CONTENT-TYPE ===", c.GetHeader("Content-Type"))
+	provider := c.Param("provider")
+
+	bodyBytes, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(apperror.BadRequest(helper.CodeInvalidNotification, helper.MsgInvalidNotification, err))
+		return
+	}
+
+	log := logger.FromContext(c.Request.Context()).With("provider", provider)
+	log.Debug("Payment notification received", "content_type", c.GetHeader("Content-Type"), "body", logger.RedactJSON(bodyBytes))
+
+	err = h.service.ProcessNotification(c.Request.Context(), provider, bodyBytes)
+	if err != nil {
+		log.Warn("Payment notification not processed", "error", err)
+
+		// Anything but a 2xx makes the provider retry the notification later
+		switch {
+		case errors.Is(err, transaction.ErrInvalidSignature):
+			c.Error(apperror.Unauthorized(helper.CodeInvalidSignature, helper.MsgInvalidSignature, err))
+		case errors.Is(err, transaction.ErrTransactionNotFound):
+			c.Error(apperror.NotFound(helper.CodeTransactionNotFound, helper.MsgTransactionNotFound, err))
+		case errors.Is(err, payment.ErrUnknownProvider):
+			c.Error(apperror.NotFound(helper.CodeUnknownProvider, helper.MsgUnknownPaymentProvider, err))
+		case errors.Is(err, payment.ErrInvalidNotification):
+			c.Error(apperror.BadRequest(helper.CodeInvalidNotification, helper.MsgInvalidNotification, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToProcessNotification, err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (h *transactionHandler) GetSupportersWall(c *gin.Context) {
+	var input transaction.GetSupportersWallInput
+
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidTransactionInput, err))
+		return
+	}
 
-	// Restore the body so it can be read again by ShouldBindJSON
-	c.Request.Body = io.NopCloser(strings.NewReader(string(bodyBytes)))
+	transactions, err := h.service.GetSupportersWall(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, transaction.ErrCampaignNotFound):
+			c.Error(apperror.NotFound(helper.CodeCampaignNotFound, helper.MsgCampaignNotFound, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToGetSupportersWall, err))
+		}
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgSupportersWallRetrievedSuccess, http.StatusOK, "success", transaction.FormatSupporterMessages(transactions))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *transactionHandler) ModerateMessage(c *gin.Context) {
+	var inputID transaction.GetTransactionInput
+
+	err := c.ShouldBindUri(&inputID)
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidTransactionID, err))
+		return
+	}
 
-	var input transaction.TransactionNotificationInput
+	var inputData transaction.ModerateMessageInput
+
+	err = c.ShouldBindJSON(&inputData)
+	if err != nil {
+		c.Error(apperror.InvalidInput(helper.MsgInvalidMessageModerationInput, err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	inputData.User = currentUser
+
+	updatedTransaction, err := h.service.ModerateMessage(c.Request.Context(), inputID, inputData)
+	if err != nil {
+		switch {
+		case errors.Is(err, transaction.ErrTransactionNotFound):
+			c.Error(apperror.NotFound(helper.CodeTransactionNotFound, helper.MsgTransactionNotFound, err))
+		case errors.Is(err, transaction.ErrNotAuthorized):
+			c.Error(apperror.Forbidden(helper.CodeNotAuthorized, helper.MsgNotAuthorizedToModerateMessage, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToModerateMessage, err))
+		}
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgMessageModeratedSuccessfully, http.StatusOK, "success", transaction.FormatTransaction(updatedTransaction))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *transactionHandler) GetCampaignBackers(c *gin.Context) {
+	var input transaction.GetCampaignBackersInput
+
+	err := c.ShouldBindUri(&input)
+	if err == nil {
+		err = c.ShouldBindQuery(&input)
+	}
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidTransactionInput, err))
+		return
+	}
+
+	transactions, total, err := h.service.GetCampaignBackers(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, transaction.ErrCampaignNotFound):
+			c.Error(apperror.NotFound(helper.CodeCampaignNotFound, helper.MsgCampaignNotFound, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToGetCampaignBackers, err))
+		}
+		return
+	}
+
+	page, limit := transaction.BackersPage(input)
+
+	response := helper.APIResponse(helper.MsgCampaignBackersRetrievedSuccess, http.StatusOK, "success", transaction.FormatBackersPage(transactions, page, limit, total))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *transactionHandler) GetLeaderboard(c *gin.Context) {
+	var input transaction.GetLeaderboardInput
+
+	err := c.ShouldBindUri(&input)
+	if err == nil {
+		err = c.ShouldBindQuery(&input)
+	}
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidTransactionInput, err))
+		return
+	}
+
+	totals, err := h.service.GetLeaderboard(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, transaction.ErrCampaignNotFound):
+			c.Error(apperror.NotFound(helper.CodeCampaignNotFound, helper.MsgCampaignNotFound, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToGetLeaderboard, err))
+		}
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgLeaderboardRetrievedSuccess, http.StatusOK, "success", transaction.FormatLeaderboard(totals))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *transactionHandler) CreateGuestTransaction(c *gin.Context) {
+	var input transaction.CreateGuestTransactionInput
 
 	err := c.ShouldBindJSON(&input)
 	if err != nil {
-		log.Println("=== BIND ERROR ===", err.Error())
-		response := helper.APIResponse("Failed to process notification", http.StatusBadRequest, "error", gin.H{"errors": err.Error()})
-		c.JSON(http.StatusBadRequest, response)
+		c.Error(apperror.InvalidInput(helper.MsgInvalidTransactionInput, err))
+		return
+	}
+
+	newTransaction, err := h.service.CreateGuestTransaction(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, transaction.ErrCampaignNotFound):
+			c.Error(apperror.NotFound(helper.CodeCampaignNotFound, helper.MsgCampaignNotFound, err))
+		case errors.Is(err, transaction.ErrAmountOutOfRange):
+			c.Error(apperror.Unprocessable(helper.CodeAmountOutOfRange, helper.MsgInvalidDonationAmount, err))
+		case errors.Is(err, transaction.ErrInvalidPerk):
+			c.Error(apperror.Unprocessable(helper.CodeInvalidPerk, helper.MsgInvalidPerk, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToCreateTransaction, err))
+		}
 		return
 	}
 
-	log.Printf("=== PARSED INPUT === %+v\n", input)
+	response := helper.APIResponse(helper.MsgTransactionCreatedSuccessfully, http.StatusCreated, "success", transaction.FormatTransaction(newTransaction))
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *transactionHandler) ClaimGuestTransactions(c *gin.Context) {
+	var input transaction.ClaimGuestTransactionsInput
 
-	err = h.service.ProcessPayment(input)
+	err := c.ShouldBindJSON(&input)
 	if err != nil {
-		log.Println("=== PROCESS PAYMENT ERROR ===", err.Error())
+		c.Error(apperror.InvalidInput(helper.MsgInvalidClaimInput, err))
+		return
+	}
 
-		if errors.Is(err, transaction.ErrInvalidSignature) {
-			response := helper.APIResponse("Invalid signature", http.StatusUnauthorized, "error", nil)
-			c.JSON(http.StatusUnauthorized, response)
-			return
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	claimed, err := h.service.ClaimGuestTransactions(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, transaction.ErrInvalidClaimToken):
+			c.Error(apperror.NotFound(helper.CodeInvalidClaimToken, helper.MsgInvalidClaimToken, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToClaimTransactions, err))
 		}
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgTransactionsClaimedSuccessfully, http.StatusOK, "success", gin.H{"claimed": claimed})
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *transactionHandler) GetReceipt(c *gin.Context) {
+	var input transaction.GetReceiptInput
 
-		if errors.Is(err, transaction.ErrTransactionNotFound) {
-			response := helper.APIResponse("Transaction not found", http.StatusNotFound, "error", nil)
-			c.JSON(http.StatusNotFound, response)
-			return
+	err := c.ShouldBindUri(&input)
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidTransactionID, err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	receipt, pdf, err := h.service.GetReceipt(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, transaction.ErrTransactionNotFound):
+			c.Error(apperror.NotFound(helper.CodeTransactionNotFound, helper.MsgTransactionNotFound, err))
+		case errors.Is(err, transaction.ErrNotAuthorized):
+			c.Error(apperror.Forbidden(helper.CodeNotAuthorized, helper.MsgNotAuthorizedToViewReceipt, err))
+		case errors.Is(err, transaction.ErrReceiptNotAvailable):
+			c.Error(apperror.Conflict(helper.CodeReceiptNotAvailable, helper.MsgReceiptNotAvailable, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToGetReceipt, err))
 		}
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+receipt.FileName+`"`)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func (h *transactionHandler) ExportCampaignTransactions(c *gin.Context) {
+	var input transaction.ExportCampaignTransactionsInput
 
-		response := helper.APIResponse("Failed to process notification", http.StatusBadRequest, "error", gin.H{"errors": err.Error()})
-		c.JSON(http.StatusBadRequest, response)
+	err := c.ShouldBindUri(&input)
+	if err == nil {
+		err = c.ShouldBindQuery(&input)
+	}
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidExportInput, err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success"})
+	currentUser := c.MustGet("currentUser").(user.User)
+	input.User = currentUser
+
+	export, err := h.service.ExportCampaignTransactions(c.Request.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, transaction.ErrCampaignNotFound):
+			c.Error(apperror.NotFound(helper.CodeCampaignNotFound, helper.MsgCampaignNotFound, err))
+		case errors.Is(err, transaction.ErrNotAuthorized):
+			c.Error(apperror.Forbidden(helper.CodeNotAuthorized, helper.MsgNotAuthorizedToExportTransactions, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToExportTransactions, err))
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+export.FileName()+`"`)
+	c.Header("Content-Type", export.ContentType())
+	c.Status(http.StatusOK)
+
+	// The body is already on its way, so a failure can only cut the download short
+	if err := export.Write(c.Request.Context(), c.Writer); err != nil {
+		logger.FromContext(c.Request.Context()).Error("Failed to export transactions", "campaign_id", input.ID, "error", err)
+	}
+}
+
+func (h *transactionHandler) GetFraudAlerts(c *gin.Context) {
+	var input transaction.GetFraudAlertsInput
+
+	err := c.ShouldBindQuery(&input)
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidFraudAlertInput, err))
+		return
+	}
+
+	alerts, err := h.service.GetFraudAlerts(c.Request.Context(), input)
+	if err != nil {
+		c.Error(apperror.Internal(helper.MsgFailedToGetFraudAlerts, err))
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgFraudAlertsRetrievedSuccess, http.StatusOK, "success", transaction.FormatFraudAlerts(alerts))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *transactionHandler) ResolveFraudAlert(c *gin.Context) {
+	var inputID transaction.GetFraudAlertInput
+
+	err := c.ShouldBindUri(&inputID)
+	if err != nil {
+		c.Error(apperror.InvalidParams(helper.MsgInvalidFraudAlertInput, err))
+		return
+	}
+
+	var inputData transaction.ResolveFraudAlertInput
+
+	err = c.ShouldBindJSON(&inputData)
+	if err != nil {
+		c.Error(apperror.InvalidInput(helper.MsgInvalidFraudAlertInput, err))
+		return
+	}
+
+	currentUser := c.MustGet("currentUser").(user.User)
+	inputData.User = currentUser
+
+	alert, err := h.service.ResolveFraudAlert(c.Request.Context(), inputID, inputData)
+	if err != nil {
+		switch {
+		case errors.Is(err, transaction.ErrFraudAlertNotFound):
+			c.Error(apperror.NotFound(helper.CodeFraudAlertNotFound, helper.MsgFraudAlertNotFound, err))
+		case errors.Is(err, transaction.ErrFraudAlertResolved):
+			c.Error(apperror.Conflict(helper.CodeFraudAlertResolved, helper.MsgFraudAlertAlreadyResolved, err))
+		case errors.Is(err, payment.ErrRefundRejected):
+			c.Error(apperror.New(http.StatusBadGateway, helper.CodeRefundRejected, helper.MsgRefundRejected, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgFailedToResolveFraudAlert, err))
+		}
+		return
+	}
+
+	response := helper.APIResponse(helper.MsgFraudAlertResolvedSuccessfully, http.StatusOK, "success", transaction.FormatFraudAlert(alert))
+	c.JSON(http.StatusOK, response)
 }
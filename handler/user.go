@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"backer/apperror"
 	"backer/auth"
 	"backer/helper"
 	"backer/user"
@@ -25,33 +26,24 @@ func (h *userHandler) RegisterUser(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&input)
 	if err != nil {
-		validationErrors := helper.FormatValidationError(err)
-		errorMessage := gin.H{"errors": validationErrors}
-
-		response := helper.APIResponse(helper.MsgInvalidInput, http.StatusUnprocessableEntity, "error", errorMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperror.InvalidInput(helper.MsgInvalidInput, err))
 		return
 	}
 
-	newUser, err := h.userService.RegisterUser(input)
+	newUser, err := h.userService.RegisterUser(c.Request.Context(), input)
 	if err != nil {
-		errorMessage := gin.H{"errors": err.Error()}
-
-		if errors.Is(err, user.ErrEmailAlreadyRegistered) {
-			response := helper.APIResponse(helper.MsgEmailAlreadyRegistered, http.StatusConflict, "error", errorMessage)
-			c.JSON(http.StatusConflict, response)
-			return
+		switch {
+		case errors.Is(err, user.ErrEmailAlreadyRegistered):
+			c.Error(apperror.Conflict(helper.CodeEmailAlreadyRegistered, helper.MsgEmailAlreadyRegistered, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgRegistrationFailed, err))
 		}
-
-		response := helper.APIResponse(helper.MsgRegistrationFailed, http.StatusBadRequest, "error", errorMessage)
-		c.JSON(http.StatusBadRequest, response)
 		return
 	}
 
 	token, err := h.authService.GenerateToken(newUser.ID)
 	if err != nil {
-		response := helper.APIResponse(helper.MsgFailedToGenerateToken, http.StatusInternalServerError, "error", nil)
-		c.JSON(http.StatusInternalServerError, response)
+		c.Error(apperror.Internal(helper.MsgFailedToGenerateToken, err))
 		return
 	}
 
@@ -66,27 +58,24 @@ func (h *userHandler) Login(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&input)
 	if err != nil {
-		validationErrors := helper.FormatValidationError(err)
-		errorMessage := gin.H{"errors": validationErrors}
-
-		response := helper.APIResponse(helper.MsgInvalidInput, http.StatusUnprocessableEntity, "error", errorMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperror.InvalidInput(helper.MsgInvalidInput, err))
 		return
 	}
 
-	loggedinUser, err := h.userService.Login(input)
+	loggedinUser, err := h.userService.Login(c.Request.Context(), input)
 	if err != nil {
-		errorMessage := gin.H{"errors": err.Error()}
-
-		response := helper.APIResponse(helper.MsgInvalidEmailOrPassword, http.StatusUnauthorized, "error", errorMessage)
-		c.JSON(http.StatusUnauthorized, response)
+		switch {
+		case errors.Is(err, user.ErrInvalidCredentials):
+			c.Error(apperror.Unauthorized(helper.CodeInvalidCredentials, helper.MsgInvalidEmailOrPassword, err))
+		default:
+			c.Error(apperror.Internal(helper.MsgLoginFailed, err))
+		}
 		return
 	}
 
 	token, err := h.authService.GenerateToken(loggedinUser.ID)
 	if err != nil {
-		response := helper.APIResponse(helper.MsgFailedToGenerateToken, http.StatusInternalServerError, "error", nil)
-		c.JSON(http.StatusInternalServerError, response)
+		c.Error(apperror.Internal(helper.MsgFailedToGenerateToken, err))
 		return
 	}
 
@@ -101,19 +90,13 @@ func (h *userHandler) CheckEmailAvailability(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&input)
 	if err != nil {
-		validationErrors := helper.FormatValidationError(err)
-		errorMessage := gin.H{"errors": validationErrors}
-
-		response := helper.APIResponse(helper.MsgEmailValidationFailed, http.StatusUnprocessableEntity, "error", errorMessage)
-		c.JSON(http.StatusUnprocessableEntity, response)
+		c.Error(apperror.InvalidInput(helper.MsgEmailValidationFailed, err))
 		return
 	}
 
-	isEmailAvailable, err := h.userService.IsEmailAvailable(input)
+	isEmailAvailable, err := h.userService.IsEmailAvailable(c.Request.Context(), input)
 	if err != nil {
-		errorMessage := gin.H{"errors": helper.MsgUnableToCheckEmailAvailability}
-		response := helper.APIResponse(helper.MsgEmailCheckFailed, http.StatusInternalServerError, "error", errorMessage)
-		c.JSON(http.StatusInternalServerError, response)
+		c.Error(apperror.Internal(helper.MsgEmailCheckFailed, err))
 		return
 	}
 
@@ -152,7 +135,7 @@ func (h *userHandler) UploadAvatar(c *gin.Context) {
 		return
 	}
 
-	_, err = h.userService.SaveAvatar(userID, path)
+	_, err = h.userService.SaveAvatar(c.Request.Context(), userID, path)
 	if err != nil {
 		data := gin.H{"is_uploaded": false}
 		response := helper.APIResponse(helper.MsgFailedToUpdateAvatar, http.StatusInternalServerError, "error", data)
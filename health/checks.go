@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// pingTimeout keeps a hanging database from stalling the probe.
+const pingTimeout = 2 * time.Second
+
+// DatabaseCheck pings the database through the connection pool.
+func DatabaseCheck(db *gorm.DB) Check {
+	return Check{
+		Name: "database",
+		Run: func() error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+			defer cancel()
+
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// WritableDirCheck verifies that files can be created in dir, which is where
+// uploads are saved.
+func WritableDirCheck(name string, dir string) Check {
+	return Check{
+		Name: name,
+		Run: func() error {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+
+			file, err := os.CreateTemp(dir, ".readyz-*")
+			if err != nil {
+				return err
+			}
+			file.Close()
+
+			return os.Remove(file.Name())
+		},
+	}
+}
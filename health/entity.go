@@ -0,0 +1,24 @@
+package health
+
+import "time"
+
+// Check is one readiness dependency. Run returns nil when the dependency is usable.
+type Check struct {
+	Name string
+	Run  func() error
+}
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Name     string
+	Error    error
+	Duration time.Duration
+}
+
+// BuildInfo identifies the running build.
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	BuildTime string
+	GoVersion string
+}
@@ -0,0 +1,54 @@
+package health
+
+type CheckResultFormatter struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+type ReadinessFormatter struct {
+	Status string                 `json:"status"`
+	Checks []CheckResultFormatter `json:"checks"`
+}
+
+// FormatReadiness lists each check as ok or fail. The checks' errors are left out:
+// /readyz is public and they can name hosts and connection settings.
+func FormatReadiness(ready bool, results []CheckResult) ReadinessFormatter {
+	formatter := ReadinessFormatter{}
+	formatter.Status = "ok"
+	if !ready {
+		formatter.Status = "fail"
+	}
+
+	formatter.Checks = []CheckResultFormatter{}
+	for _, result := range results {
+		checkFormatter := CheckResultFormatter{}
+		checkFormatter.Name = result.Name
+		checkFormatter.Status = "ok"
+		if result.Error != nil {
+			checkFormatter.Status = "fail"
+		}
+		checkFormatter.DurationMS = float64(result.Duration.Microseconds()) / 1000
+
+		formatter.Checks = append(formatter.Checks, checkFormatter)
+	}
+
+	return formatter
+}
+
+type BuildInfoFormatter struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+func FormatBuildInfo(buildInfo BuildInfo) BuildInfoFormatter {
+	formatter := BuildInfoFormatter{}
+	formatter.Version = buildInfo.Version
+	formatter.Commit = buildInfo.Commit
+	formatter.BuildTime = buildInfo.BuildTime
+	formatter.GoVersion = buildInfo.GoVersion
+
+	return formatter
+}
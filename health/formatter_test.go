@@ -0,0 +1,29 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFormatReadinessLeavesErrorsOut(t *testing.T) {
+	results := []CheckResult{
+		{Name: "database", Error: errors.New("dial tcp db.internal:3306: connect: connection refused")},
+		{Name: "uploads"},
+	}
+
+	body, err := json.Marshal(FormatReadiness(false, results))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(body), "db.internal") {
+		t.Errorf("readiness = %s, want the check error left out", body)
+	}
+
+	want := `{"status":"fail","checks":[{"name":"database","status":"fail","duration_ms":0},{"name":"uploads","status":"ok","duration_ms":0}]}`
+	if string(body) != want {
+		t.Errorf("readiness = %s, want %s", body, want)
+	}
+}
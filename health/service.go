@@ -0,0 +1,87 @@
+package health
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+type Service interface {
+	Ready() (bool, []CheckResult)
+	BuildInfo() BuildInfo
+}
+
+type service struct {
+	checks    []Check
+	buildInfo BuildInfo
+}
+
+// NewService takes the build details injected at build time; empty ones are filled
+// from the VCS information Go embeds in the binary, if any.
+func NewService(buildInfo BuildInfo, checks ...Check) *service {
+	return &service{
+		checks:    checks,
+		buildInfo: completeBuildInfo(buildInfo),
+	}
+}
+
+// Ready runs every check, even after one fails, so the response shows all of them.
+func (s *service) Ready() (bool, []CheckResult) {
+	ready := true
+	results := []CheckResult{}
+
+	for _, check := range s.checks {
+		start := time.Now()
+		err := check.Run()
+
+		if err != nil {
+			ready = false
+		}
+
+		results = append(results, CheckResult{Name: check.Name, Error: err, Duration: time.Since(start)})
+	}
+
+	return ready, results
+}
+
+func (s *service) BuildInfo() BuildInfo {
+	return s.buildInfo
+}
+
+func completeBuildInfo(buildInfo BuildInfo) BuildInfo {
+	buildInfo.GoVersion = runtime.Version()
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		info = &debug.BuildInfo{}
+	}
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if buildInfo.Commit == "" {
+				buildInfo.Commit = setting.Value
+			}
+		case "vcs.time":
+			if buildInfo.BuildTime == "" {
+				buildInfo.BuildTime = setting.Value
+			}
+		}
+	}
+
+	if buildInfo.Version == "" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		buildInfo.Version = info.Main.Version
+	}
+
+	if buildInfo.Version == "" {
+		buildInfo.Version = "dev"
+	}
+	if buildInfo.Commit == "" {
+		buildInfo.Commit = "unknown"
+	}
+	if buildInfo.BuildTime == "" {
+		buildInfo.BuildTime = "unknown"
+	}
+
+	return buildInfo
+}
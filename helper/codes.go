@@ -0,0 +1,61 @@
+package helper
+
+// Common error codes, returned as data.code in error responses
+const (
+	CodeInternalError = "internal_error"
+	CodeInvalidParams = "invalid_params"
+	CodeInvalidInput  = "invalid_input"
+	CodeTimeout       = "timeout"
+	CodeUnauthorized  = "unauthorized"
+	CodeAdminOnly     = "admin_only"
+)
+
+// Campaign error codes
+const (
+	CodeNotAuthorized          = "not_authorized"
+	CodeCampaignNotFound       = "campaign_not_found"
+	CodeInvalidMinimumDonation = "invalid_minimum_donation"
+)
+
+// Transaction error codes
+const (
+	CodeTransactionNotFound      = "transaction_not_found"
+	CodeAmountOutOfRange         = "amount_out_of_range"
+	CodeInvalidPerk              = "invalid_perk"
+	CodeTransactionNotRefundable = "transaction_not_refundable"
+	CodeRefundAmountExceeded     = "refund_amount_exceeded"
+	CodeRefundRejected           = "refund_rejected"
+	CodeInvalidClaimToken        = "invalid_claim_token"
+	CodeReceiptNotAvailable      = "receipt_not_available"
+	CodeInvalidSignature         = "invalid_signature"
+	CodeUnknownProvider          = "unknown_provider"
+	CodeInvalidNotification      = "invalid_notification"
+)
+
+// Fraud alert error codes
+const (
+	CodeFraudAlertNotFound = "fraud_alert_not_found"
+	CodeFraudAlertResolved = "fraud_alert_resolved"
+)
+
+// Ledger error codes
+const (
+	CodePayoutNotFound        = "payout_not_found"
+	CodePayoutAlreadyReviewed = "payout_already_reviewed"
+	CodeBankAccountRequired   = "bank_account_required"
+	CodeInsufficientBalance   = "insufficient_balance"
+	CodeBankAccountNotFound   = "bank_account_not_found"
+)
+
+// Pledge error codes
+const (
+	CodePledgeNotFound      = "pledge_not_found"
+	CodeInvalidStatusChange = "invalid_status_change"
+	CodeFirstChargeFailed   = "first_charge_failed"
+)
+
+// User error codes
+const (
+	CodeEmailAlreadyRegistered = "email_already_registered"
+	CodeInvalidCredentials     = "invalid_credentials"
+)
@@ -1,6 +1,12 @@
 package helper
 
-import "github.com/go-playground/validator/v10"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
 
 type Response struct {
 	Meta Meta        `json:"meta"`
@@ -29,6 +35,12 @@ func APIResponse(message string, code int, status string, data interface{}) Resp
 }
 
 func FormatValidationError(err error) []string {
+	// Values that don't fit their field, e.g. an amount overflowing int or sent as text
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		return []string{fmt.Sprintf("%s must be a valid %s", typeError.Field, typeError.Type)}
+	}
+
 	var errors []string
 
 	validationErrors, ok := err.(validator.ValidationErrors)
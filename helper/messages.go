@@ -2,27 +2,30 @@ package helper
 
 // Common messages
 const (
-	MsgInvalidInput = "Invalid input"
+	MsgInvalidInput        = "Invalid input"
+	MsgInternalServerError = "Internal server error"
+	MsgRequestTimeout      = "Request timed out"
+	MsgUnauthorized        = "Unauthorized"
 )
 
 // User messages
 const (
-	MsgEmailAlreadyRegistered         = "Email already registered"
-	MsgRegistrationFailed             = "Registration failed"
-	MsgFailedToGenerateToken          = "Failed to generate authentication token"
-	MsgAccountRegisteredSuccessfully  = "Account registered successfully"
-	MsgInvalidEmailOrPassword         = "Invalid email or password"
-	MsgSuccessfullyLoggedIn           = "Successfully logged in"
-	MsgEmailValidationFailed          = "Email validation failed"
-	MsgEmailCheckFailed               = "Email check failed"
-	MsgUserDataRetrievedSuccessfully  = "User data retrieved successfully"
-	MsgUnableToCheckEmailAvailability = "Unable to check email availability"
-	MsgEmailAlreadyRegisteredCheck    = "Email is already registered"
-	MsgEmailAvailable                 = "Email is available"
-	MsgNoAvatarFileProvided           = "No avatar file provided"
-	MsgFailedToSaveAvatarFile         = "Failed to save avatar file"
-	MsgFailedToUpdateAvatar           = "Failed to update avatar"
-	MsgAvatarUploadedSuccessfully     = "Avatar uploaded successfully"
+	MsgEmailAlreadyRegistered        = "Email already registered"
+	MsgRegistrationFailed            = "Registration failed"
+	MsgFailedToGenerateToken         = "Failed to generate authentication token"
+	MsgAccountRegisteredSuccessfully = "Account registered successfully"
+	MsgInvalidEmailOrPassword        = "Invalid email or password"
+	MsgSuccessfullyLoggedIn          = "Successfully logged in"
+	MsgLoginFailed                   = "Login failed"
+	MsgEmailValidationFailed         = "Email validation failed"
+	MsgEmailCheckFailed              = "Email check failed"
+	MsgUserDataRetrievedSuccessfully = "User data retrieved successfully"
+	MsgEmailAlreadyRegisteredCheck   = "Email is already registered"
+	MsgEmailAvailable                = "Email is available"
+	MsgNoAvatarFileProvided          = "No avatar file provided"
+	MsgFailedToSaveAvatarFile        = "Failed to save avatar file"
+	MsgFailedToUpdateAvatar          = "Failed to update avatar"
+	MsgAvatarUploadedSuccessfully    = "Avatar uploaded successfully"
 )
 
 // Campaign messages
@@ -31,6 +34,7 @@ const (
 	MsgListOfCampaignsRetrieved          = "List of campaigns retrieved successfully"
 	MsgInvalidCampaignID                 = "Invalid campaign ID"
 	MsgCampaignNotFound                  = "Campaign not found"
+	MsgFailedToGetCampaign               = "Failed to get campaign"
 	MsgCampaignDetailRetrieved           = "Campaign detail retrieved successfully"
 	MsgFailedToCreateCampaign            = "Failed to create campaign"
 	MsgCampaignCreatedSuccessfully       = "Campaign created successfully"
@@ -44,6 +48,7 @@ const (
 	MsgNotAuthorizedToUploadImage        = "You are not authorized to upload image for this campaign"
 	MsgFailedToSaveImageToDatabase       = "Failed to save image to database"
 	MsgCampaignImageUploadedSuccessfully = "Campaign image uploaded successfully"
+	MsgInvalidMinimumDonation            = "Minimum donation exceeds the maximum donation amount"
 )
 
 // Transaction messages
@@ -56,4 +61,101 @@ const (
 	MsgUserTransactionsRetrievedSuccess     = "User transactions retrieved successfully"
 	MsgFailedToCreateTransaction            = "Failed to create transaction"
 	MsgTransactionCreatedSuccessfully       = "Transaction created successfully"
+	MsgInvalidTransactionID                 = "Invalid transaction ID"
+	MsgTransactionNotFound                  = "Transaction not found"
+	MsgInvalidRefundInput                   = "Invalid refund input"
+	MsgNotAuthorizedToRefundTransaction     = "You are not authorized to refund this transaction"
+	MsgTransactionNotRefundable             = "Transaction cannot be refunded"
+	MsgRefundAmountExceeded                 = "Refund amount exceeds the refundable amount"
+	MsgRefundRejected                       = "Refund was rejected by the payment provider"
+	MsgFailedToRefundTransaction            = "Failed to refund transaction"
+	MsgRefundCreatedSuccessfully            = "Refund created successfully"
+	MsgFailedToGetSupportersWall            = "Failed to get supporters wall"
+	MsgSupportersWallRetrievedSuccess       = "Supporters wall retrieved successfully"
+	MsgInvalidMessageModerationInput        = "Invalid message moderation input"
+	MsgNotAuthorizedToModerateMessage       = "You are not authorized to moderate this message"
+	MsgFailedToModerateMessage              = "Failed to moderate message"
+	MsgMessageModeratedSuccessfully         = "Message moderated successfully"
+	MsgFailedToGetCampaignBackers           = "Failed to get campaign backers"
+	MsgCampaignBackersRetrievedSuccess      = "Campaign backers retrieved successfully"
+	MsgFailedToGetLeaderboard               = "Failed to get leaderboard"
+	MsgLeaderboardRetrievedSuccess          = "Leaderboard retrieved successfully"
+	MsgInvalidClaimInput                    = "Invalid claim input"
+	MsgInvalidClaimToken                    = "Claim link is invalid or has expired"
+	MsgFailedToClaimTransactions            = "Failed to claim donations"
+	MsgTransactionsClaimedSuccessfully      = "Donations claimed successfully"
+	MsgNotAuthorizedToViewReceipt           = "You are not authorized to view this receipt"
+	MsgReceiptNotAvailable                  = "Receipt is only available for paid transactions"
+	MsgFailedToGetReceipt                   = "Failed to get receipt"
+	MsgInvalidExportInput                   = "Invalid export input"
+	MsgNotAuthorizedToExportTransactions    = "You are not authorized to export these transactions"
+	MsgFailedToExportTransactions           = "Failed to export transactions"
+	MsgInvalidPerk                          = "Perk is not offered by this campaign"
+	MsgInvalidDonationAmount                = "Invalid donation amount"
+	MsgInvalidSignature                     = "Invalid signature"
+	MsgUnknownPaymentProvider               = "Unknown payment provider"
+	MsgInvalidNotification                  = "Invalid notification"
+	MsgFailedToProcessNotification          = "Failed to process notification"
+)
+
+// Admin messages
+const (
+	MsgAdminOnly                      = "Only admins can access this resource"
+	MsgInvalidFraudAlertInput         = "Invalid fraud alert input"
+	MsgFailedToGetFraudAlerts         = "Failed to get fraud alerts"
+	MsgFraudAlertsRetrievedSuccess    = "Fraud alerts retrieved successfully"
+	MsgFraudAlertNotFound             = "Fraud alert not found"
+	MsgFraudAlertAlreadyResolved      = "Fraud alert is already resolved"
+	MsgFailedToResolveFraudAlert      = "Failed to resolve fraud alert"
+	MsgFraudAlertResolvedSuccessfully = "Fraud alert resolved successfully"
+)
+
+// Ledger messages
+const (
+	MsgInvalidBankAccountInput       = "Invalid bank account input"
+	MsgFailedToGetBankAccount        = "Failed to get bank account"
+	MsgBankAccountNotFound           = "Bank account not found"
+	MsgBankAccountRetrievedSuccess   = "Bank account retrieved successfully"
+	MsgFailedToSaveBankAccount       = "Failed to save bank account"
+	MsgBankAccountSavedSuccessfully  = "Bank account saved successfully"
+	MsgNotAuthorizedToViewBalance    = "You are not authorized to view this campaign's balance"
+	MsgFailedToGetBalance            = "Failed to get balance"
+	MsgBalanceRetrievedSuccess       = "Balance retrieved successfully"
+	MsgInvalidPayoutInput            = "Invalid payout input"
+	MsgBankAccountRequired           = "Register a bank account before requesting a payout"
+	MsgInsufficientBalance           = "Payout amount exceeds the available balance"
+	MsgFailedToRequestPayout         = "Failed to request payout"
+	MsgPayoutRequestedSuccessfully   = "Payout requested successfully"
+	MsgFailedToGetPayouts            = "Failed to get payouts"
+	MsgPayoutsRetrievedSuccess       = "Payouts retrieved successfully"
+	MsgPayoutNotFound                = "Payout not found"
+	MsgPayoutAlreadyReviewed         = "Payout is already reviewed"
+	MsgFailedToReviewPayout          = "Failed to review payout"
+	MsgPayoutReviewedSuccessfully    = "Payout reviewed successfully"
+	MsgFailedToGetBalanceReport      = "Failed to get balance report"
+	MsgBalanceReportRetrievedSuccess = "Balance report retrieved successfully"
+)
+
+// Pledge messages
+const (
+	MsgInvalidPledgeInput           = "Invalid pledge input"
+	MsgInvalidPledgeID              = "Invalid pledge ID"
+	MsgFailedToGetPledges           = "Failed to get pledges"
+	MsgPledgesRetrievedSuccessfully = "Pledges retrieved successfully"
+	MsgFailedToCreatePledge         = "Failed to create pledge"
+	MsgPledgeChargeFailed           = "The first charge of the pledge failed"
+	MsgPledgeCreatedSuccessfully    = "Pledge created successfully"
+	MsgPledgeNotFound               = "Pledge not found"
+	MsgNotAuthorizedToUpdatePledge  = "You are not authorized to update this pledge"
+	MsgInvalidPledgeStatusChange    = "Pledge cannot change to this status"
+	MsgFailedToUpdatePledge         = "Failed to update pledge"
+	MsgPledgeUpdatedSuccessfully    = "Pledge updated successfully"
+)
+
+// Health messages
+const (
+	MsgServiceAlive              = "Service is alive"
+	MsgServiceReady              = "Service is ready"
+	MsgServiceNotReady           = "Service is not ready"
+	MsgBuildInfoRetrievedSuccess = "Build info retrieved successfully"
 )
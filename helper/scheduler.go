@@ -0,0 +1,21 @@
+package helper
+
+import (
+	"context"
+	"time"
+)
+
+// RunEvery calls job once per interval until ctx is cancelled.
+func RunEvery(ctx context.Context, interval time.Duration, job func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job()
+		}
+	}
+}
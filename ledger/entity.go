@@ -0,0 +1,87 @@
+package ledger
+
+import (
+	"backer/campaign"
+	"time"
+)
+
+// Journal groups the balanced entries of one money movement. Reference makes
+// posting idempotent, e.g. "donation:TRX-..." is only ever recorded once.
+type Journal struct {
+	ID          int
+	Kind        string
+	Reference   string `gorm:"uniqueIndex;size:128"`
+	CampaignID  int    `gorm:"index"`
+	Description string
+	Entries     []Entry
+	CreatedAt   time.Time
+}
+
+func (Journal) TableName() string {
+	return "ledger_journals"
+}
+
+// Entry moves Debit or Credit on one account. The entries of a journal always
+// balance.
+type Entry struct {
+	ID         int
+	JournalID  int `gorm:"index"`
+	Account    string
+	CampaignID int `gorm:"index"`
+	Debit      int
+	Credit     int
+	CreatedAt  time.Time
+}
+
+func (Entry) TableName() string {
+	return "ledger_entries"
+}
+
+// BankAccount is where a campaign owner receives payouts.
+type BankAccount struct {
+	ID            int
+	UserID        int `gorm:"uniqueIndex"`
+	BankName      string
+	AccountNumber string
+	AccountHolder string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Payout is an owner's request to withdraw a campaign's available balance. The bank
+// details are copied so later changes to the bank account don't rewrite history.
+type Payout struct {
+	ID            int
+	CampaignID    int `gorm:"index"`
+	UserID        int
+	Amount        int
+	Status        string
+	BankName      string
+	AccountNumber string
+	AccountHolder string
+	ReviewedBy    int
+	Note          string
+	Campaign      campaign.Campaign
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// CampaignBalance summarizes a campaign's money movements.
+type CampaignBalance struct {
+	CampaignID     int
+	Donations      int
+	PlatformFees   int
+	GatewayFees    int
+	Refunds        int
+	PaidOut        int
+	PendingPayouts int
+	Available      int
+}
+
+// BalanceReport is the platform-wide view for admins.
+type BalanceReport struct {
+	Campaigns       []CampaignBalance
+	PlatformRevenue int
+	GatewayFees     int
+	Cash            int
+}
@@ -0,0 +1,105 @@
+package ledger
+
+import "backer/helper"
+
+type BankAccountFormatter struct {
+	BankName      string `json:"bank_name"`
+	AccountNumber string `json:"account_number"`
+	AccountHolder string `json:"account_holder"`
+}
+
+func FormatBankAccount(bankAccount BankAccount) BankAccountFormatter {
+	formatter := BankAccountFormatter{}
+	formatter.BankName = bankAccount.BankName
+	formatter.AccountNumber = bankAccount.AccountNumber
+	formatter.AccountHolder = bankAccount.AccountHolder
+
+	return formatter
+}
+
+type CampaignBalanceFormatter struct {
+	CampaignID     int `json:"campaign_id"`
+	Donations      int `json:"donations"`
+	PlatformFees   int `json:"platform_fees"`
+	GatewayFees    int `json:"gateway_fees"`
+	Refunds        int `json:"refunds"`
+	PaidOut        int `json:"paid_out"`
+	PendingPayouts int `json:"pending_payouts"`
+	Available      int `json:"available"`
+}
+
+func FormatCampaignBalance(balance CampaignBalance) CampaignBalanceFormatter {
+	formatter := CampaignBalanceFormatter{}
+	formatter.CampaignID = balance.CampaignID
+	formatter.Donations = balance.Donations
+	formatter.PlatformFees = balance.PlatformFees
+	formatter.GatewayFees = balance.GatewayFees
+	formatter.Refunds = balance.Refunds
+	formatter.PaidOut = balance.PaidOut
+	formatter.PendingPayouts = balance.PendingPayouts
+	formatter.Available = balance.Available
+
+	return formatter
+}
+
+type BalanceReportFormatter struct {
+	PlatformRevenue int                        `json:"platform_revenue"`
+	GatewayFees     int                        `json:"gateway_fees"`
+	Cash            int                        `json:"cash"`
+	Campaigns       []CampaignBalanceFormatter `json:"campaigns"`
+}
+
+func FormatBalanceReport(report BalanceReport) BalanceReportFormatter {
+	formatter := BalanceReportFormatter{}
+	formatter.PlatformRevenue = report.PlatformRevenue
+	formatter.GatewayFees = report.GatewayFees
+	formatter.Cash = report.Cash
+	formatter.Campaigns = []CampaignBalanceFormatter{}
+
+	for _, balance := range report.Campaigns {
+		formatter.Campaigns = append(formatter.Campaigns, FormatCampaignBalance(balance))
+	}
+
+	return formatter
+}
+
+type PayoutFormatter struct {
+	ID            int    `json:"id"`
+	CampaignID    int    `json:"campaign_id"`
+	CampaignName  string `json:"campaign_name"`
+	UserID        int    `json:"user_id"`
+	Amount        int    `json:"amount"`
+	Status        string `json:"status"`
+	BankName      string `json:"bank_name"`
+	AccountNumber string `json:"account_number"`
+	AccountHolder string `json:"account_holder"`
+	Note          string `json:"note"`
+	CreatedAt     string `json:"created_at"`
+}
+
+func FormatPayout(payout Payout) PayoutFormatter {
+	formatter := PayoutFormatter{}
+	formatter.ID = payout.ID
+	formatter.CampaignID = payout.CampaignID
+	formatter.CampaignName = payout.Campaign.Name
+	formatter.UserID = payout.UserID
+	formatter.Amount = payout.Amount
+	formatter.Status = payout.Status
+	formatter.BankName = payout.BankName
+	formatter.AccountNumber = payout.AccountNumber
+	formatter.AccountHolder = payout.AccountHolder
+	formatter.Note = payout.Note
+	formatter.CreatedAt = payout.CreatedAt.Format(helper.DateTimeFormat)
+
+	return formatter
+}
+
+func FormatPayouts(payouts []Payout) []PayoutFormatter {
+	payoutsFormatter := []PayoutFormatter{}
+
+	for _, payout := range payouts {
+		payoutsFormatter = append(payoutsFormatter, FormatPayout(payout))
+	}
+
+	return payoutsFormatter
+}
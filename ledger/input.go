@@ -0,0 +1,33 @@
+package ledger
+
+import "backer/user"
+
+type GetCampaignLedgerInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
+
+type SaveBankAccountInput struct {
+	BankName      string `json:"bank_name" binding:"required,max=100"`
+	AccountNumber string `json:"account_number" binding:"required,numeric,max=34"`
+	AccountHolder string `json:"account_holder" binding:"required,max=255"`
+	User          user.User
+}
+
+type RequestPayoutInput struct {
+	Amount int `json:"amount" binding:"required,gt=0"`
+	User   user.User
+}
+
+type GetPayoutsInput struct {
+	Status string `form:"status" binding:"omitempty,oneof=requested paid rejected"`
+}
+
+type GetPayoutInput struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+type ReviewPayoutInput struct {
+	Note string `json:"note"`
+	User user.User
+}
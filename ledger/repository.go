@@ -0,0 +1,216 @@
+package ledger
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	SaveJournals(ctx context.Context, journals []Journal) error
+	GetAccountBalances(ctx context.Context, campaignID int) ([]AccountBalance, error)
+	FindBankAccountByUserID(ctx context.Context, userID int) (BankAccount, error)
+	SaveBankAccount(ctx context.Context, bankAccount BankAccount) (BankAccount, error)
+	SavePayoutRequest(ctx context.Context, payout Payout) (Payout, error)
+	FindPayoutByID(ctx context.Context, ID int) (Payout, error)
+	FindPayoutsByCampaignID(ctx context.Context, campaignID int) ([]Payout, error)
+	FindPayouts(ctx context.Context, status string) ([]Payout, error)
+	ReviewPayout(ctx context.Context, payout Payout, journal Journal) (Payout, error)
+}
+
+// AccountBalance is the aggregated movement of one account by journal kind.
+type AccountBalance struct {
+	CampaignID int
+	Kind       string
+	Account    string
+	Debit      int
+	Credit     int
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+// SaveJournals posts journals atomically, skipping any whose reference was already
+// posted.
+func (r *repository) SaveJournals(ctx context.Context, journals []Journal) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return PostJournals(tx, journals)
+	})
+}
+
+// PostJournals saves journals within tx, skipping any whose reference was already
+// posted. Other repositories use it to book money movements in the same database
+// transaction as the change that causes them.
+func PostJournals(tx *gorm.DB, journals []Journal) error {
+	for _, journal := range journals {
+		if err := saveJournal(tx, journal); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func saveJournal(tx *gorm.DB, journal Journal) error {
+	var existing int64
+
+	err := tx.Model(&Journal{}).Where("reference = ?", journal.Reference).Count(&existing).Error
+	if err != nil {
+		return err
+	}
+
+	if existing > 0 {
+		return nil
+	}
+
+	return tx.Create(&journal).Error
+}
+
+// GetAccountBalances sums entries per campaign, journal kind and account. A zero
+// campaignID covers all campaigns.
+func (r *repository) GetAccountBalances(ctx context.Context, campaignID int) ([]AccountBalance, error) {
+	var balances []AccountBalance
+
+	query := r.db.WithContext(ctx).Table("ledger_entries").
+		Select("ledger_entries.campaign_id, ledger_journals.kind, ledger_entries.account, SUM(ledger_entries.debit) AS debit, SUM(ledger_entries.credit) AS credit").
+		Joins("JOIN ledger_journals ON ledger_journals.id = ledger_entries.journal_id").
+		Group("ledger_entries.campaign_id, ledger_journals.kind, ledger_entries.account").
+		Order("ledger_entries.campaign_id")
+
+	if campaignID != 0 {
+		query = query.Where("ledger_entries.campaign_id = ?", campaignID)
+	}
+
+	err := query.Scan(&balances).Error
+	if err != nil {
+		return balances, err
+	}
+
+	return balances, nil
+}
+
+func (r *repository) FindBankAccountByUserID(ctx context.Context, userID int) (BankAccount, error) {
+	var bankAccount BankAccount
+
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Limit(1).Find(&bankAccount).Error
+	if err != nil {
+		return bankAccount, err
+	}
+
+	return bankAccount, nil
+}
+
+func (r *repository) SaveBankAccount(ctx context.Context, bankAccount BankAccount) (BankAccount, error) {
+	err := r.db.WithContext(ctx).Save(&bankAccount).Error
+	if err != nil {
+		return bankAccount, err
+	}
+
+	return bankAccount, nil
+}
+
+// SavePayoutRequest reserves the payout amount from the campaign's available
+// balance. The campaign row is locked so concurrent requests can't both spend the
+// same balance; SQLite has no row locks but only ever runs one writer.
+func (r *repository) SavePayoutRequest(ctx context.Context, payout Payout) (Payout, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var campaignID int
+		err := tx.Table("campaigns").Select("id").Where("id = ?", payout.CampaignID).Clauses(clause.Locking{Strength: "UPDATE"}).Scan(&campaignID).Error
+		if err != nil {
+			return err
+		}
+
+		var available int
+		err = tx.Table("ledger_entries").
+			Select("COALESCE(SUM(credit) - SUM(debit), 0)").
+			Where("campaign_id = ? AND account = ?", payout.CampaignID, AccountCampaignPayable).
+			Scan(&available).Error
+		if err != nil {
+			return err
+		}
+
+		if payout.Amount > available {
+			return ErrInsufficientBalance
+		}
+
+		err = tx.Omit("Campaign").Create(&payout).Error
+		if err != nil {
+			return err
+		}
+
+		return saveJournal(tx, payoutRequestJournal(payout))
+	})
+	if err != nil {
+		return payout, err
+	}
+
+	return payout, nil
+}
+
+func (r *repository) FindPayoutByID(ctx context.Context, ID int) (Payout, error) {
+	var payout Payout
+
+	err := r.db.WithContext(ctx).Preload("Campaign").Where("id = ?", ID).Find(&payout).Error
+	if err != nil {
+		return payout, err
+	}
+
+	return payout, nil
+}
+
+func (r *repository) FindPayoutsByCampaignID(ctx context.Context, campaignID int) ([]Payout, error) {
+	var payouts []Payout
+
+	err := r.db.WithContext(ctx).Where("campaign_id = ?", campaignID).Order("id desc").Find(&payouts).Error
+	if err != nil {
+		return payouts, err
+	}
+
+	return payouts, nil
+}
+
+func (r *repository) FindPayouts(ctx context.Context, status string) ([]Payout, error) {
+	var payouts []Payout
+
+	query := r.db.WithContext(ctx).Preload("Campaign")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Order("id asc").Find(&payouts).Error
+	if err != nil {
+		return payouts, err
+	}
+
+	return payouts, nil
+}
+
+// ReviewPayout stores an approved or rejected payout together with its journal,
+// as long as the payout is still waiting for review.
+func (r *repository) ReviewPayout(ctx context.Context, payout Payout, journal Journal) (Payout, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Payout{}).
+			Where("id = ? AND status = ?", payout.ID, "requested").
+			Updates(map[string]interface{}{"status": payout.Status, "reviewed_by": payout.ReviewedBy, "note": payout.Note})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrPayoutAlreadyReviewed
+		}
+
+		return saveJournal(tx, journal)
+	})
+	if err != nil {
+		return payout, err
+	}
+
+	return payout, nil
+}
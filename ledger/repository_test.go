@@ -0,0 +1,124 @@
+package ledger
+
+import (
+	"backer/database/databasetest"
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func newTestRepository(t *testing.T) (*repository, *gorm.DB) {
+	t.Helper()
+
+	t.Setenv("PLATFORM_FEE_PERCENT", "5")
+	t.Setenv("GATEWAY_FEE_PERCENT", "0")
+	t.Setenv("GATEWAY_FEE_FIXED", "1000")
+	db := databasetest.Open(t)
+
+	return NewRepository(db), db
+}
+
+func campaignBalance(t *testing.T, r *repository, campaignID int) CampaignBalance {
+	t.Helper()
+
+	balances, err := r.GetAccountBalances(context.Background(), campaignID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := buildBalanceReport(balances)
+	if len(report.Campaigns) != 1 {
+		t.Fatalf("balance report has %d campaigns, want 1", len(report.Campaigns))
+	}
+
+	return report.Campaigns[0]
+}
+
+func TestSaveJournals(t *testing.T) {
+	r, db := newTestRepository(t)
+	ctx := context.Background()
+
+	journals := DonationJournals(7, "TRX-1", 100000)
+
+	// A replayed settlement posts nothing twice
+	for range 2 {
+		if err := r.SaveJournals(ctx, journals); err != nil {
+			t.Fatalf("SaveJournals: %v", err)
+		}
+	}
+
+	var journalCount, entryCount int64
+	if err := db.Model(&Journal{}).Count(&journalCount).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&Entry{}).Count(&entryCount).Error; err != nil {
+		t.Fatal(err)
+	}
+	if journalCount != 3 || entryCount != 6 {
+		t.Errorf("ledger has %d journals and %d entries, want 3 and 6", journalCount, entryCount)
+	}
+
+	balance := campaignBalance(t, r, 7)
+	want := CampaignBalance{CampaignID: 7, Donations: 100000, PlatformFees: 5000, GatewayFees: 1000, Available: 94000}
+	if balance != want {
+		t.Errorf("balance = %+v, want %+v", balance, want)
+	}
+
+	if err := r.SaveJournals(ctx, []Journal{RefundJournal(7, "TRX-1-refund-1", 30000)}); err != nil {
+		t.Fatalf("SaveJournals: %v", err)
+	}
+
+	balance = campaignBalance(t, r, 7)
+	if balance.Refunds != 30000 || balance.Available != 64000 {
+		t.Errorf("balance after a refund = %+v, want 30000 refunded and 64000 available", balance)
+	}
+}
+
+func TestSavePayoutRequest(t *testing.T) {
+	r, _ := newTestRepository(t)
+	ctx := context.Background()
+
+	if err := r.SaveJournals(ctx, DonationJournals(7, "TRX-1", 100000)); err != nil {
+		t.Fatalf("SaveJournals: %v", err)
+	}
+
+	if _, err := r.SavePayoutRequest(ctx, Payout{CampaignID: 7, UserID: 1, Amount: 94001, Status: "requested"}); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("requesting more than the balance = %v, want %v", err, ErrInsufficientBalance)
+	}
+
+	payout, err := r.SavePayoutRequest(ctx, Payout{CampaignID: 7, UserID: 1, Amount: 60000, Status: "requested"})
+	if err != nil {
+		t.Fatalf("SavePayoutRequest: %v", err)
+	}
+
+	// The requested payout holds its amount until it is reviewed
+	if _, err := r.SavePayoutRequest(ctx, Payout{CampaignID: 7, UserID: 1, Amount: 40000, Status: "requested"}); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("requesting the amount held by a pending payout = %v, want %v", err, ErrInsufficientBalance)
+	}
+
+	balance := campaignBalance(t, r, 7)
+	if balance.PendingPayouts != 60000 || balance.Available != 34000 {
+		t.Errorf("balance = %+v, want 60000 pending and 34000 available", balance)
+	}
+
+	payout.Status = "rejected"
+	rejectJournal := newJournal(KindPayoutReject, "payout_reject:1", 7, "Rejected payout 1",
+		Entry{Account: AccountPayoutsPending, Debit: payout.Amount},
+		Entry{Account: AccountCampaignPayable, Credit: payout.Amount},
+	)
+
+	if _, err := r.ReviewPayout(ctx, payout, rejectJournal); err != nil {
+		t.Fatalf("ReviewPayout: %v", err)
+	}
+
+	if _, err := r.ReviewPayout(ctx, payout, rejectJournal); !errors.Is(err, ErrPayoutAlreadyReviewed) {
+		t.Errorf("reviewing a payout twice = %v, want %v", err, ErrPayoutAlreadyReviewed)
+	}
+
+	balance = campaignBalance(t, r, 7)
+	if balance.PendingPayouts != 0 || balance.Available != 94000 {
+		t.Errorf("balance after the rejection = %+v, want nothing pending and 94000 available", balance)
+	}
+}
@@ -0,0 +1,391 @@
+package ledger
+
+import (
+	"backer/campaign"
+	"backer/config"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Custom errors
+var (
+	ErrCampaignNotFound      = errors.New("campaign not found")
+	ErrNotAuthorized         = errors.New("not authorized")
+	ErrBankAccountRequired   = errors.New("register a bank account before requesting a payout")
+	ErrInsufficientBalance   = errors.New("payout amount exceeds the available balance")
+	ErrPayoutNotFound        = errors.New("payout not found")
+	ErrPayoutAlreadyReviewed = errors.New("payout is already reviewed")
+)
+
+// Accounts
+const (
+	// AccountCash is the money the platform holds at the gateway and in the bank.
+	AccountCash = "cash"
+	// AccountCampaignPayable is what the platform owes a campaign's owner.
+	AccountCampaignPayable = "campaign_payable"
+	// AccountPayoutsPending holds payouts requested but not sent yet.
+	AccountPayoutsPending = "payouts_pending"
+	// AccountPlatformRevenue collects platform fees.
+	AccountPlatformRevenue = "platform_revenue"
+)
+
+// Journal kinds
+const (
+	KindDonation      = "donation"
+	KindPlatformFee   = "platform_fee"
+	KindGatewayFee    = "gateway_fee"
+	KindRefund        = "refund"
+	KindPayoutRequest = "payout_request"
+	KindPayout        = "payout"
+	KindPayoutReject  = "payout_reject"
+)
+
+var tracer = otel.Tracer("backer/ledger")
+
+type Service interface {
+	GetBankAccount(ctx context.Context, userID int) (BankAccount, error)
+	SaveBankAccount(ctx context.Context, input SaveBankAccountInput) (BankAccount, error)
+	GetCampaignBalance(ctx context.Context, input GetCampaignLedgerInput) (CampaignBalance, error)
+	GetCampaignPayouts(ctx context.Context, input GetCampaignLedgerInput) ([]Payout, error)
+	RequestPayout(ctx context.Context, inputID GetCampaignLedgerInput, inputData RequestPayoutInput) (Payout, error)
+	GetPayouts(ctx context.Context, input GetPayoutsInput) ([]Payout, error)
+	ApprovePayout(ctx context.Context, inputID GetPayoutInput, inputData ReviewPayoutInput) (Payout, error)
+	RejectPayout(ctx context.Context, inputID GetPayoutInput, inputData ReviewPayoutInput) (Payout, error)
+	GetBalanceReport(ctx context.Context) (BalanceReport, error)
+}
+
+type service struct {
+	repository         Repository
+	campaignRepository campaign.Repository
+}
+
+func NewService(repository Repository, campaignRepository campaign.Repository) *service {
+	return &service{repository, campaignRepository}
+}
+
+// DonationJournals books a paid donation along with the platform and gateway fees
+// taken from it. The gateway keeps its fee out of the cash it settles, and both fees
+// are borne by the campaign. The journals are posted by whoever marks the
+// transaction paid, in the same database transaction, see PostJournals.
+func DonationJournals(campaignID int, code string, amount int) []Journal {
+	journals := []Journal{
+		newJournal(KindDonation, "donation:"+code, campaignID, "Donation "+code,
+			Entry{Account: AccountCash, Debit: amount},
+			Entry{Account: AccountCampaignPayable, Credit: amount},
+		),
+	}
+
+	platformFee := percentOf(amount, config.AppConfig.PlatformFeePercent)
+	if platformFee > 0 {
+		journals = append(journals, newJournal(KindPlatformFee, "platform_fee:"+code, campaignID, "Platform fee "+code,
+			Entry{Account: AccountCampaignPayable, Debit: platformFee},
+			Entry{Account: AccountPlatformRevenue, Credit: platformFee},
+		))
+	}
+
+	gatewayFee := percentOf(amount, config.AppConfig.GatewayFeePercent) + config.AppConfig.GatewayFeeFixed
+	if gatewayFee > 0 {
+		journals = append(journals, newJournal(KindGatewayFee, "gateway_fee:"+code, campaignID, "Gateway fee "+code,
+			Entry{Account: AccountCampaignPayable, Debit: gatewayFee},
+			Entry{Account: AccountCash, Credit: gatewayFee},
+		))
+	}
+
+	return journals
+}
+
+// RefundJournal books money returned to a backer. Fees already taken are not
+// returned to the campaign. Like the donation journals, it is posted along with the
+// refund it books, see PostJournals.
+func RefundJournal(campaignID int, refundKey string, amount int) Journal {
+	return newJournal(KindRefund, "refund:"+refundKey, campaignID, "Refund "+refundKey,
+		Entry{Account: AccountCampaignPayable, Debit: amount},
+		Entry{Account: AccountCash, Credit: amount},
+	)
+}
+
+func (s *service) GetBankAccount(ctx context.Context, userID int) (BankAccount, error) {
+	ctx, span := tracer.Start(ctx, "ledger.GetBankAccount")
+	defer span.End()
+
+	bankAccount, err := s.repository.FindBankAccountByUserID(ctx, userID)
+	if err != nil {
+		return bankAccount, err
+	}
+
+	return bankAccount, nil
+}
+
+func (s *service) SaveBankAccount(ctx context.Context, input SaveBankAccountInput) (BankAccount, error) {
+	ctx, span := tracer.Start(ctx, "ledger.SaveBankAccount")
+	defer span.End()
+
+	bankAccount, err := s.repository.FindBankAccountByUserID(ctx, input.User.ID)
+	if err != nil {
+		return bankAccount, err
+	}
+
+	bankAccount.UserID = input.User.ID
+	bankAccount.BankName = input.BankName
+	bankAccount.AccountNumber = input.AccountNumber
+	bankAccount.AccountHolder = input.AccountHolder
+
+	savedBankAccount, err := s.repository.SaveBankAccount(ctx, bankAccount)
+	if err != nil {
+		return savedBankAccount, err
+	}
+
+	return savedBankAccount, nil
+}
+
+func (s *service) GetCampaignBalance(ctx context.Context, input GetCampaignLedgerInput) (CampaignBalance, error) {
+	ctx, span := tracer.Start(ctx, "ledger.GetCampaignBalance")
+	defer span.End()
+
+	if _, err := s.findOwnCampaign(ctx, input); err != nil {
+		return CampaignBalance{}, err
+	}
+
+	balances, err := s.repository.GetAccountBalances(ctx, input.ID)
+	if err != nil {
+		return CampaignBalance{}, err
+	}
+
+	report := buildBalanceReport(balances)
+	if len(report.Campaigns) == 0 {
+		return CampaignBalance{CampaignID: input.ID}, nil
+	}
+
+	return report.Campaigns[0], nil
+}
+
+func (s *service) GetCampaignPayouts(ctx context.Context, input GetCampaignLedgerInput) ([]Payout, error) {
+	ctx, span := tracer.Start(ctx, "ledger.GetCampaignPayouts")
+	defer span.End()
+
+	if _, err := s.findOwnCampaign(ctx, input); err != nil {
+		return []Payout{}, err
+	}
+
+	payouts, err := s.repository.FindPayoutsByCampaignID(ctx, input.ID)
+	if err != nil {
+		return payouts, err
+	}
+
+	return payouts, nil
+}
+
+// RequestPayout reserves part of a campaign's available balance to be sent to the
+// owner's bank account once an admin approves it.
+func (s *service) RequestPayout(ctx context.Context, inputID GetCampaignLedgerInput, inputData RequestPayoutInput) (Payout, error) {
+	ctx, span := tracer.Start(ctx, "ledger.RequestPayout")
+	defer span.End()
+
+	inputID.User = inputData.User
+
+	campaign, err := s.findOwnCampaign(ctx, inputID)
+	if err != nil {
+		return Payout{}, err
+	}
+
+	bankAccount, err := s.repository.FindBankAccountByUserID(ctx, inputData.User.ID)
+	if err != nil {
+		return Payout{}, err
+	}
+
+	if bankAccount.ID == 0 {
+		return Payout{}, ErrBankAccountRequired
+	}
+
+	payout := Payout{}
+	payout.CampaignID = campaign.ID
+	payout.UserID = inputData.User.ID
+	payout.Amount = inputData.Amount
+	payout.Status = "requested"
+	payout.BankName = bankAccount.BankName
+	payout.AccountNumber = bankAccount.AccountNumber
+	payout.AccountHolder = bankAccount.AccountHolder
+
+	newPayout, err := s.repository.SavePayoutRequest(ctx, payout)
+	if err != nil {
+		return newPayout, err
+	}
+
+	newPayout.Campaign = campaign
+
+	return newPayout, nil
+}
+
+func (s *service) GetPayouts(ctx context.Context, input GetPayoutsInput) ([]Payout, error) {
+	ctx, span := tracer.Start(ctx, "ledger.GetPayouts")
+	defer span.End()
+
+	payouts, err := s.repository.FindPayouts(ctx, input.Status)
+	if err != nil {
+		return payouts, err
+	}
+
+	return payouts, nil
+}
+
+// ApprovePayout records that the payout was sent to the owner's bank account.
+func (s *service) ApprovePayout(ctx context.Context, inputID GetPayoutInput, inputData ReviewPayoutInput) (Payout, error) {
+	ctx, span := tracer.Start(ctx, "ledger.ApprovePayout")
+	defer span.End()
+
+	return s.reviewPayout(ctx, inputID, inputData, "paid", func(payout Payout) Journal {
+		return newJournal(KindPayout, fmt.Sprintf("payout:%d", payout.ID), payout.CampaignID, fmt.Sprintf("Payout %d", payout.ID),
+			Entry{Account: AccountPayoutsPending, Debit: payout.Amount},
+			Entry{Account: AccountCash, Credit: payout.Amount},
+		)
+	})
+}
+
+// RejectPayout releases the reserved amount back to the campaign's balance.
+func (s *service) RejectPayout(ctx context.Context, inputID GetPayoutInput, inputData ReviewPayoutInput) (Payout, error) {
+	ctx, span := tracer.Start(ctx, "ledger.RejectPayout")
+	defer span.End()
+
+	return s.reviewPayout(ctx, inputID, inputData, "rejected", func(payout Payout) Journal {
+		return newJournal(KindPayoutReject, fmt.Sprintf("payout_reject:%d", payout.ID), payout.CampaignID, fmt.Sprintf("Rejected payout %d", payout.ID),
+			Entry{Account: AccountPayoutsPending, Debit: payout.Amount},
+			Entry{Account: AccountCampaignPayable, Credit: payout.Amount},
+		)
+	})
+}
+
+func (s *service) reviewPayout(ctx context.Context, inputID GetPayoutInput, inputData ReviewPayoutInput, status string, journal func(payout Payout) Journal) (Payout, error) {
+	payout, err := s.repository.FindPayoutByID(ctx, inputID.ID)
+	if err != nil {
+		return payout, err
+	}
+
+	if payout.ID == 0 {
+		return payout, ErrPayoutNotFound
+	}
+
+	if payout.Status != "requested" {
+		return payout, ErrPayoutAlreadyReviewed
+	}
+
+	payout.Status = status
+	payout.ReviewedBy = inputData.User.ID
+	payout.Note = inputData.Note
+
+	return s.repository.ReviewPayout(ctx, payout, journal(payout))
+}
+
+func (s *service) GetBalanceReport(ctx context.Context) (BalanceReport, error) {
+	ctx, span := tracer.Start(ctx, "ledger.GetBalanceReport")
+	defer span.End()
+
+	balances, err := s.repository.GetAccountBalances(ctx, 0)
+	if err != nil {
+		return BalanceReport{}, err
+	}
+
+	return buildBalanceReport(balances), nil
+}
+
+func (s *service) findOwnCampaign(ctx context.Context, input GetCampaignLedgerInput) (campaign.Campaign, error) {
+	campaign, err := s.campaignRepository.FindByID(ctx, input.ID)
+	if err != nil {
+		return campaign, err
+	}
+
+	if campaign.ID == 0 {
+		return campaign, ErrCampaignNotFound
+	}
+
+	if campaign.UserID != input.User.ID {
+		return campaign, ErrNotAuthorized
+	}
+
+	return campaign, nil
+}
+
+func newJournal(kind string, reference string, campaignID int, description string, entries ...Entry) Journal {
+	for i := range entries {
+		entries[i].CampaignID = campaignID
+	}
+
+	return Journal{
+		Kind:        kind,
+		Reference:   reference,
+		CampaignID:  campaignID,
+		Description: description,
+		Entries:     entries,
+	}
+}
+
+func payoutRequestJournal(payout Payout) Journal {
+	return newJournal(KindPayoutRequest, fmt.Sprintf("payout_request:%d", payout.ID), payout.CampaignID, fmt.Sprintf("Payout request %d", payout.ID),
+		Entry{Account: AccountCampaignPayable, Debit: payout.Amount},
+		Entry{Account: AccountPayoutsPending, Credit: payout.Amount},
+	)
+}
+
+// percentOf rounds a percentage of amount to whole rupiah.
+func percentOf(amount int, percent float64) int {
+	return int(math.Round(float64(amount) * percent / 100))
+}
+
+// buildBalanceReport folds aggregated account movements into per-campaign
+// balances and platform totals.
+func buildBalanceReport(balances []AccountBalance) BalanceReport {
+	report := BalanceReport{}
+	campaigns := map[int]*CampaignBalance{}
+	var order []int
+
+	for _, balance := range balances {
+		net := balance.Credit - balance.Debit
+
+		switch balance.Account {
+		case AccountPlatformRevenue:
+			report.PlatformRevenue += net
+			continue
+		case AccountCash:
+			report.Cash += balance.Debit - balance.Credit
+			continue
+		}
+
+		campaignBalance, ok := campaigns[balance.CampaignID]
+		if !ok {
+			campaignBalance = &CampaignBalance{CampaignID: balance.CampaignID}
+			campaigns[balance.CampaignID] = campaignBalance
+			order = append(order, balance.CampaignID)
+		}
+
+		if balance.Account == AccountPayoutsPending {
+			campaignBalance.PendingPayouts += net
+			if balance.Kind == KindPayout {
+				campaignBalance.PaidOut += balance.Debit
+			}
+			continue
+		}
+
+		campaignBalance.Available += net
+
+		switch balance.Kind {
+		case KindDonation:
+			campaignBalance.Donations += net
+		case KindPlatformFee:
+			campaignBalance.PlatformFees -= net
+		case KindGatewayFee:
+			campaignBalance.GatewayFees -= net
+			report.GatewayFees -= net
+		case KindRefund:
+			campaignBalance.Refunds -= net
+		}
+	}
+
+	for _, campaignID := range order {
+		report.Campaigns = append(report.Campaigns, *campaigns[campaignID])
+	}
+
+	return report
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// redacted replaces the value of every secret that would otherwise end up in a log line.
+const redacted = "[REDACTED]"
+
+// secretKeys are attribute and JSON keys whose values are never logged.
+var secretKeys = map[string]bool{
+	"password":      true,
+	"password_hash": true,
+	"signature_key": true,
+	"signature":     true,
+	"server_key":    true,
+	"token":         true,
+	"card_token":    true,
+	"claim_token":   true,
+	"authorization": true,
+	"secret":        true,
+	"api_key":       true,
+}
+
+// Setup makes a JSON or text logger at the given level the default for slog and
+// for the standard log package.
+func Setup(w io.Writer, level string, format string) error {
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid LOG_LEVEL %q: %w", level, err)
+	}
+
+	options := &slog.HandlerOptions{
+		Level:       logLevel,
+		ReplaceAttr: redactAttr,
+	}
+
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	case "text":
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return fmt.Errorf("invalid LOG_FORMAT %q, use json or text", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+
+	return nil
+}
+
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if isSecret(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+
+	return attr
+}
+
+func isSecret(key string) bool {
+	return secretKeys[strings.ToLower(key)]
+}
+
+// RedactJSON returns a JSON body with the values of secret keys replaced, for
+// logging payloads such as payment notifications. A body that isn't JSON is not
+// logged at all, since there is no telling what it contains.
+func RedactJSON(body []byte) string {
+	var payload any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Sprintf("<%d bytes, not JSON>", len(body))
+	}
+
+	redacted, err := json.Marshal(redactValue(payload))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+
+	return string(redacted)
+}
+
+func redactValue(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, nested := range typed {
+			if isSecret(key) {
+				typed[key] = redacted
+				continue
+			}
+			typed[key] = redactValue(nested)
+		}
+	case []any:
+		for i, nested := range typed {
+			typed[i] = redactValue(nested)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"backer/helper"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the request ID in from a proxy and back out to the client.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds an incoming request ID so clients can't bloat every log line.
+const maxRequestIDLength = 128
+
+type contextKey struct{}
+
+// requestInfo is shared by everything handling one request. The user is only known
+// once the auth middleware ran, so it is filled in later.
+type requestInfo struct {
+	requestID string
+	method    string
+	route     string
+	userID    int
+}
+
+// FromContext returns the default logger with the trace ID and the request ID,
+// method, route and user of the request in ctx, if any.
+func FromContext(ctx context.Context) *slog.Logger {
+	logger := slog.Default()
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		logger = logger.With("trace_id", spanContext.TraceID().String())
+	}
+
+	info, ok := ctx.Value(contextKey{}).(*requestInfo)
+	if !ok {
+		return logger
+	}
+
+	logger = logger.With("request_id", info.requestID, "method", info.method, "route", info.route)
+	if info.userID != 0 {
+		logger = logger.With("user_id", info.userID)
+	}
+
+	return logger
+}
+
+// RequestID returns the ID of the request in ctx, or "" outside a request.
+func RequestID(ctx context.Context) string {
+	info, ok := ctx.Value(contextKey{}).(*requestInfo)
+	if !ok {
+		return ""
+	}
+
+	return info.requestID
+}
+
+// SetUserID adds the authenticated user to the log lines of the request in ctx.
+func SetUserID(ctx context.Context, userID int) {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		info.userID = userID
+	}
+}
+
+// Middleware assigns every request an ID, reusing a sane X-Request-ID from the
+// client or proxy, and writes one access log line per request. Requests to
+// skipPaths are not logged.
+func Middleware(skipPaths ...string) gin.HandlerFunc {
+	skip := map[string]bool{}
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		info := &requestInfo{requestID: requestID, method: c.Request.Method, route: c.FullPath()}
+		if info.route == "" {
+			info.route = "unmatched"
+		}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextKey{}, info))
+
+		c.Next()
+
+		if skip[c.Request.URL.Path] {
+			return
+		}
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		FromContext(c.Request.Context()).Log(c.Request.Context(), level, "Request handled",
+			"path", c.Request.URL.Path,
+			"status", status,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000,
+			"client_ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
+		)
+	}
+}
+
+// Recovery turns a panic into a 500 in the usual response envelope and logs it with
+// its stack and request details instead of gin's plain text dump.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		FromContext(c.Request.Context()).Error("panic while handling request",
+			"panic", fmt.Sprint(recovered),
+			"stack", string(debug.Stack()),
+		)
+		response := helper.APIResponse(helper.MsgInternalServerError, http.StatusInternalServerError, "error", nil)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response)
+	})
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
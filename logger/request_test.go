@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"backer/helper"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecoveryAnswersInTheResponseEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Recovery())
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusInternalServerError)
+	}
+
+	var response helper.Response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("body %q is not a JSON response: %v", recorder.Body.String(), err)
+	}
+
+	want := helper.Meta{Message: helper.MsgInternalServerError, Code: http.StatusInternalServerError, Status: "error"}
+	if response.Meta != want {
+		t.Errorf("meta = %+v, want %+v", response.Meta, want)
+	}
+}
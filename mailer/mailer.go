@@ -0,0 +1,201 @@
+package mailer
+
+import (
+	"backer/logger"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To          string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+type Attachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// Mailer sends transactional emails to backers.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+type smtpMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+	timeout  time.Duration
+}
+
+func NewSMTPMailer(host string, port string, username string, password string, from string, timeout time.Duration) *smtpMailer {
+	return &smtpMailer{host, port, username, password, from, timeout}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, message Message) error {
+	// MAIL_FROM may carry a display name, the SMTP envelope only takes the address
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %w", m.from, err)
+	}
+
+	body, err := m.compose(message)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	return m.deliver(ctx, from.Address, message.To, body)
+}
+
+// deliver does what smtp.SendMail does, upgrading to TLS when the server offers it,
+// but gives up at ctx's deadline so an unresponsive server can't hold the sender.
+func (m *smtpMailer) deliver(ctx context.Context, from string, to string, body []byte) error {
+	if strings.ContainsAny(from+to, "\r\n") {
+		return errors.New("smtp: address contains a line break")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.host, m.port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(body); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// compose builds the raw email, as multipart/mixed when there are attachments.
+func (m *smtpMailer) compose(message Message) ([]byte, error) {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if len(message.Attachments) == 0 {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		b.WriteString("\r\n")
+		b.WriteString(message.Body)
+		return b.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&b)
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=%s\r\n", writer.Boundary())
+	b.WriteString("\r\n")
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}})
+	if err != nil {
+		return nil, err
+	}
+	part.Write([]byte(message.Body))
+
+	for _, attachment := range message.Attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", attachment.FileName)},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		// RFC 2045 limits encoded lines to 76 characters
+		for len(encoded) > 76 {
+			fmt.Fprintf(part, "%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		fmt.Fprintf(part, "%s\r\n", encoded)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// logMailer logs that an email would have been sent, for local development without
+// an SMTP server. The body is left out since it can carry links that grant access,
+// such as a guest's claim link.
+type logMailer struct{}
+
+func NewLogMailer() *logMailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(ctx context.Context, message Message) error {
+	var attachments []string
+	for _, attachment := range message.Attachments {
+		attachments = append(attachments, fmt.Sprintf("%s (%d bytes)", attachment.FileName, len(attachment.Data)))
+	}
+
+	logger.FromContext(ctx).Info("Email not sent, SMTP is not configured", "to", message.To, "subject", message.Subject, "attachments", strings.Join(attachments, ", "))
+	return nil
+}
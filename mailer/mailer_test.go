@@ -0,0 +1,76 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogMailerLeavesTheBodyOut(t *testing.T) {
+	var output bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&output, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	err := NewLogMailer().Send(context.Background(), Message{
+		To:      "guest@example.com",
+		Subject: "Your donation receipt",
+		Body:    "Claim it at https://backer.example/claim-donation?token=secret-token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logged := output.String()
+	if strings.Contains(logged, "secret-token") {
+		t.Errorf("log contains the email body: %s", logged)
+	}
+	if !strings.Contains(logged, "guest@example.com") || !strings.Contains(logged, "Your donation receipt") {
+		t.Errorf("log = %s, want the recipient and subject", logged)
+	}
+}
+
+func TestSMTPMailerGivesUpOnASilentServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	// Accept the connection but never send the greeting
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+	t.Cleanup(func() {
+		select {
+		case conn := <-accepted:
+			conn.Close()
+		default:
+		}
+	})
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := time.Now()
+	err = NewSMTPMailer(host, port, "", "", "Backer <no-reply@backer.local>", 200*time.Millisecond).Send(context.Background(), Message{
+		To:      "guest@example.com",
+		Subject: "Your donation receipt",
+		Body:    "Thank you",
+	})
+	if err == nil {
+		t.Fatal("Send succeeded against a server that never answered")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Send returned after %s, want it to give up at the timeout", elapsed)
+	}
+}
@@ -1,63 +1,166 @@
 package main
 
 import (
+	"backer/apperror"
 	"backer/auth"
 	"backer/campaign"
 	"backer/config"
+	"backer/database"
 	"backer/handler"
+	"backer/health"
 	"backer/helper"
+	"backer/ledger"
+	"backer/logger"
+	"backer/mailer"
+	"backer/metrics"
 	"backer/payment"
+	"backer/pledge"
+	"backer/tracing"
 	"backer/transaction"
 	"backer/user"
-	"fmt"
-	"log"
-	"net/http"
+	"context"
+	"log/slog"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
 )
 
+// Build details, set with e.g.
+// go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildTime=$(date -u +%FT%TZ)"
+var (
+	version   string
+	commit    string
+	buildTime string
+)
+
+// probePaths are polled constantly by the orchestrator and Prometheus and kept out
+// of the request log.
+var probePaths = []string{"/healthz", "/readyz", "/version", "/metrics"}
+
 func main() {
 	// Load .env file
 	err := godotenv.Load()
 	if err != nil {
-		log.Println(".env file not found, using system environment variables")
+		slog.Info(".env file not found, using system environment variables")
 	}
 
 	// Load config from .env or environment variables
 	config.LoadConfig()
 
-	// Database connection (configure this to match your .env values)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root", "", "localhost", "3306", "backer")
+	if err := logger.Setup(os.Stdout, config.AppConfig.LogLevel, config.AppConfig.LogFormat); err != nil {
+		fatal("Invalid log settings", "error", err)
+	}
+	slog.Info("Config loaded", "image_base_url", config.AppConfig.ImageBaseURL)
+
+	db, err := database.Open(config.AppConfig)
+	if err != nil {
+		fatal("Failed to connect to database", "error", err)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:], db)
+		return
+	}
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	// Refuse to serve against a schema this build doesn't know how to use
+	if err := database.CheckSchema(db); err != nil {
+		fatal("Schema check failed", "error", err)
+	}
+
+	if err := metrics.InstrumentDB(db); err != nil {
+		fatal("Failed to instrument database", "error", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), config.AppConfig.TracingExporter, config.AppConfig.TracingSampleRatio, version)
+	if err != nil {
+		fatal("Failed to set up tracing", "error", err)
+	}
+	if err := tracing.InstrumentDB(db); err != nil {
+		fatal("Failed to instrument database", "error", err)
+	}
 
 	// Repository
 	userRepository := user.NewRepository(db)
 	campaignRepository := campaign.NewRepository(db)
 	transactionRepository := transaction.NewRepository(db)
+	pledgeRepository := pledge.NewRepository(db)
+	ledgerRepository := ledger.NewRepository(db)
 
 	// Service
 	userService := user.NewService(userRepository)
 	authService := auth.NewService()
 	campaignService := campaign.NewService(campaignRepository)
-	paymentService := payment.NewService()
-	transactionService := transaction.NewService(transactionRepository, campaignRepository, paymentService)
+	paymentRegistry := payment.NewRegistry(config.AppConfig.PaymentProvider, payment.NewMidtransProvider())
+
+	// The fake provider is only available when explicitly selected, it must never run in production
+	fakeProvider := payment.NewFakeProvider(config.AppConfig.AppBaseURL, config.AppConfig.FakePaymentServerKey)
+	if config.AppConfig.PaymentProvider == fakeProvider.Name() {
+		paymentRegistry.Register(fakeProvider)
+	}
+
+	if _, err := paymentRegistry.Get(""); err != nil {
+		fatal("Invalid PAYMENT_PROVIDER", "error", err)
+	}
+	paymentService := payment.NewService(paymentRegistry)
+
+	// Without SMTP settings emails are written to the log, which is enough for local development
+	var appMailer mailer.Mailer = mailer.NewLogMailer()
+	if config.AppConfig.SMTPHost != "" {
+		appMailer = mailer.NewSMTPMailer(config.AppConfig.SMTPHost, config.AppConfig.SMTPPort, config.AppConfig.SMTPUsername, config.AppConfig.SMTPPassword, config.AppConfig.MailFrom, config.AppConfig.SMTPTimeout)
+	}
+
+	ledgerService := ledger.NewService(ledgerRepository, campaignRepository)
+	transactionService := transaction.NewService(transactionRepository, campaignRepository, paymentService, appMailer)
+	pledgeService := pledge.NewService(pledgeRepository, campaignRepository, transactionService)
+
+	// CLI subcommands, e.g. `backer reconcile`
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:], transactionService)
+		transactionService.WaitForReceipts(context.Background())
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Warn("Flushing traces failed", "error", err)
+		}
+		return
+	}
+
+	// Background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	startReconcileWorker(workerCtx, &workers, transactionService)
+	startExpirySweeper(workerCtx, &workers, transactionService)
+	startPledgeBilling(workerCtx, &workers, pledgeService)
 
 	// Handler
 	userHandler := handler.NewUserHandler(userService, authService)
 	campaignHandler := handler.NewCampaignHandler(campaignService)
 	transactionHandler := handler.NewTransactionHandler(transactionService)
+	pledgeHandler := handler.NewPledgeHandler(pledgeService)
+	ledgerHandler := handler.NewLedgerHandler(ledgerService)
+
+	healthService := health.NewService(
+		health.BuildInfo{Version: version, Commit: commit, BuildTime: buildTime},
+		health.DatabaseCheck(db),
+		health.WritableDirCheck("uploads", "images"),
+		health.Check{Name: "payment_provider", Run: paymentService.CheckConfig},
+	)
+	healthHandler := handler.NewHealthHandler(healthService)
 
 	// Router
-	router := gin.Default()
+	router := gin.New()
+	// Tracing goes first so the request log lines carry the trace ID
+	router.Use(tracing.Middleware(probePaths...), logger.Middleware(probePaths...), logger.Recovery(), metrics.Middleware())
+	router.Use(apperror.Middleware())
+
+	// Exports stream every transaction of a campaign and only answer to the write timeout
+	router.Use(requestTimeout(config.AppConfig.ServerRequestTimeout, map[string]time.Duration{
+		"/api/v1/campaigns/:id/transactions/export": config.AppConfig.ServerWriteTimeout,
+	}))
 
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
@@ -70,6 +173,19 @@ func main() {
 
 	router.Static("/images", "./images")
 
+	// Probes for the orchestrator, outside /api/v1 and without auth
+	router.GET("/healthz", healthHandler.Liveness)
+	router.GET("/readyz", healthHandler.Readiness)
+	router.GET("/version", healthHandler.Version)
+	router.GET("/metrics", metrics.Handler(config.AppConfig.MetricsToken))
+
+	// Local checkout pages of the fake payment provider
+	if config.AppConfig.PaymentProvider == fakeProvider.Name() {
+		fakePaymentHandler := handler.NewFakePaymentHandler(fakeProvider)
+		router.GET("/dev/checkout/:order_id", fakePaymentHandler.ShowCheckout)
+		router.POST("/dev/checkout/:order_id/:outcome", fakePaymentHandler.CompleteCheckout)
+	}
+
 	api := router.Group("/api/v1")
 
 	// User routes
@@ -88,11 +204,75 @@ func main() {
 
 	// Transaction routes
 	api.GET("/campaigns/:id/transactions", authMiddleware(authService, userService), transactionHandler.GetCampaignTransactions)
+	api.GET("/campaigns/:id/transactions/export", authMiddleware(authService, userService), transactionHandler.ExportCampaignTransactions)
+	api.GET("/campaigns/:id/supporters", transactionHandler.GetSupportersWall)
+	api.GET("/campaigns/:id/backers", transactionHandler.GetCampaignBackers)
+	api.GET("/campaigns/:id/leaderboard", transactionHandler.GetLeaderboard)
 	api.GET("/transactions", authMiddleware(authService, userService), transactionHandler.GetUserTransactions)
 	api.POST("/transactions", authMiddleware(authService, userService), transactionHandler.CreateTransaction)
+	api.POST("/transactions/guest", transactionHandler.CreateGuestTransaction)
+	api.POST("/transactions/claim", authMiddleware(authService, userService), transactionHandler.ClaimGuestTransactions)
 	api.POST("/transactions/notification", transactionHandler.GetNotification)
+	api.POST("/transactions/notification/:provider", transactionHandler.GetNotification)
+	api.POST("/transactions/:id/refunds", authMiddleware(authService, userService), transactionHandler.RefundTransaction)
+	api.GET("/transactions/:id/receipt", authMiddleware(authService, userService), transactionHandler.GetReceipt)
+	api.PATCH("/transactions/:id/message", authMiddleware(authService, userService), transactionHandler.ModerateMessage)
+
+	// Ledger routes
+	api.GET("/bank-account", authMiddleware(authService, userService), ledgerHandler.GetBankAccount)
+	api.PUT("/bank-account", authMiddleware(authService, userService), ledgerHandler.SaveBankAccount)
+	api.GET("/campaigns/:id/balance", authMiddleware(authService, userService), ledgerHandler.GetCampaignBalance)
+	api.GET("/campaigns/:id/payouts", authMiddleware(authService, userService), ledgerHandler.GetCampaignPayouts)
+	api.POST("/campaigns/:id/payouts", authMiddleware(authService, userService), ledgerHandler.RequestPayout)
 
-	router.Run(":8080")
+	// Pledge routes
+	api.GET("/pledges", authMiddleware(authService, userService), pledgeHandler.GetPledges)
+	api.POST("/pledges", authMiddleware(authService, userService), pledgeHandler.CreatePledge)
+	api.POST("/pledges/:id/pause", authMiddleware(authService, userService), pledgeHandler.PausePledge)
+	api.POST("/pledges/:id/resume", authMiddleware(authService, userService), pledgeHandler.ResumePledge)
+	api.POST("/pledges/:id/cancel", authMiddleware(authService, userService), pledgeHandler.CancelPledge)
+
+	// Admin routes
+	admin := api.Group("/admin", authMiddleware(authService, userService), adminMiddleware())
+	admin.GET("/fraud-alerts", transactionHandler.GetFraudAlerts)
+	admin.POST("/fraud-alerts/:id/resolve", transactionHandler.ResolveFraudAlert)
+	admin.GET("/payouts", ledgerHandler.GetPayouts)
+	admin.POST("/payouts/:id/approve", ledgerHandler.ApprovePayout)
+	admin.POST("/payouts/:id/reject", ledgerHandler.RejectPayout)
+	admin.GET("/balances", ledgerHandler.GetBalanceReport)
+
+	serve(router, db, stopWorkers, &workers, transactionService.WaitForReceipts, shutdownTracing)
+}
+
+// requestTimeout puts a deadline on the context of every request, so a slow query or
+// payment provider fails the request instead of holding it open. Routes in
+// overrides, by route template, get their own timeout.
+func requestTimeout(timeout time.Duration, overrides map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		routeTimeout, ok := overrides[c.FullPath()]
+		if !ok {
+			routeTimeout = timeout
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), routeTimeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// adminMiddleware only lets admins through. It must run after authMiddleware.
+func adminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser := c.MustGet("currentUser").(user.User)
+
+		if currentUser.Role != "admin" {
+			c.Error(apperror.Forbidden(helper.CodeAdminOnly, helper.MsgAdminOnly, nil))
+			c.Abort()
+			return
+		}
+	}
 }
 
 func authMiddleware(authService auth.Service, userService user.Service) gin.HandlerFunc {
@@ -100,8 +280,7 @@ func authMiddleware(authService auth.Service, userService user.Service) gin.Hand
 		authHeader := c.GetHeader("Authorization")
 
 		if !strings.Contains(authHeader, "Bearer") {
-			response := helper.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			abortUnauthorized(c, nil)
 			return
 		}
 
@@ -113,28 +292,38 @@ func authMiddleware(authService auth.Service, userService user.Service) gin.Hand
 
 		token, err := authService.ValidateToken(tokenString)
 		if err != nil {
-			response := helper.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			abortUnauthorized(c, err)
 			return
 		}
 
 		claim, ok := token.Claims.(jwt.MapClaims)
 
 		if !ok || !token.Valid {
-			response := helper.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			abortUnauthorized(c, nil)
 			return
 		}
 
 		userID := int(claim["user_id"].(float64))
 
-		user, err := userService.GetUserByID(userID)
+		user, err := userService.GetUserByID(c.Request.Context(), userID)
 		if err != nil {
-			response := helper.APIResponse("Unauthorized", http.StatusUnauthorized, "error", nil)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			abortUnauthorized(c, err)
 			return
 		}
 
 		c.Set("currentUser", user)
+		logger.SetUserID(c.Request.Context(), user.ID)
 	}
 }
+
+// abortUnauthorized rejects a request without a valid token. err, if any, is only logged.
+func abortUnauthorized(c *gin.Context, err error) {
+	c.Error(apperror.Unauthorized(helper.CodeUnauthorized, helper.MsgUnauthorized, err))
+	c.Abort()
+}
+
+// fatal logs an error that keeps the app from running and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const startTimeKey = "metrics:start_time"
+
+// InstrumentDB times every gorm query and exports the connection pool stats.
+func InstrumentDB(db *gorm.DB) error {
+	callback := db.Callback()
+	err := errors.Join(
+		callback.Create().Before("*").Register("metrics:before_create", startTimer),
+		callback.Create().After("*").Register("metrics:after_create", observeQuery("create")),
+		callback.Query().Before("*").Register("metrics:before_query", startTimer),
+		callback.Query().After("*").Register("metrics:after_query", observeQuery("query")),
+		callback.Update().Before("*").Register("metrics:before_update", startTimer),
+		callback.Update().After("*").Register("metrics:after_update", observeQuery("update")),
+		callback.Delete().Before("*").Register("metrics:before_delete", startTimer),
+		callback.Delete().After("*").Register("metrics:after_delete", observeQuery("delete")),
+		callback.Row().Before("*").Register("metrics:before_row", startTimer),
+		callback.Row().After("*").Register("metrics:after_row", observeQuery("row")),
+		callback.Raw().Before("*").Register("metrics:before_raw", startTimer),
+		callback.Raw().After("*").Register("metrics:after_raw", observeQuery("raw")),
+	)
+	if err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return prometheus.Register(collectors.NewDBStatsCollector(sqlDB, namespace))
+}
+
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(startTimeKey, time.Now())
+}
+
+func observeQuery(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startTimeKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		dbQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			dbQueryErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
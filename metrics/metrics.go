@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace prefixes every metric of the app, e.g. backer_http_requests_total.
+const namespace = "backer"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	dbQueryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Failed database queries by operation and table, not counting record not found.",
+	}, []string{"operation", "table"})
+
+	transactionsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_created_total",
+		Help:      "Transactions created by payment provider.",
+	}, []string{"provider"})
+
+	transactionsPaid = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_paid_total",
+		Help:      "Transactions that became paid by payment provider.",
+	}, []string{"provider"})
+
+	transactionsCancelled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_cancelled_total",
+		Help:      "Transactions that were cancelled by payment provider.",
+	}, []string{"provider"})
+
+	signatureFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payment_notification_signature_failures_total",
+		Help:      "Payment notifications rejected for an invalid signature by payment provider.",
+	}, []string{"provider"})
+
+	amountRaised = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "amount_raised_rupiah_total",
+		Help:      "Rupiah raised by paid transactions by payment provider, before refunds.",
+	}, []string{"provider"})
+)
+
+// TransactionCreated counts a new transaction sent to the payment provider.
+func TransactionCreated(provider string) {
+	transactionsCreated.WithLabelValues(provider).Inc()
+}
+
+// TransactionPaid counts a transaction that became paid and adds its amount to
+// the amount raised.
+func TransactionPaid(provider string, amount int) {
+	transactionsPaid.WithLabelValues(provider).Inc()
+	amountRaised.WithLabelValues(provider).Add(float64(amount))
+}
+
+// TransactionCancelled counts a transaction that failed, expired at the provider
+// or was rejected after review.
+func TransactionCancelled(provider string) {
+	transactionsCancelled.WithLabelValues(provider).Inc()
+}
+
+// SignatureFailure counts a notification whose signature did not verify.
+func SignatureFailure(provider string) {
+	signatureFailures.WithLabelValues(provider).Inc()
+}
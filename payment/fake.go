@@ -0,0 +1,222 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Outcomes a developer can pick on the fake checkout page
+const (
+	FakeOutcomePay    = "pay"
+	FakeOutcomeFail   = "fail"
+	FakeOutcomeExpire = "expire"
+)
+
+// FakeDeclinedCardToken is a saved card token the fake provider always declines.
+const FakeDeclinedCardToken = "declined"
+
+// FakeCheckout is the developer-facing side of the fake provider, used by the
+// local checkout page.
+type FakeCheckout interface {
+	GetOrder(orderID string) (FakeOrder, bool)
+	Complete(ctx context.Context, orderID string, outcome string) error
+}
+
+type FakeOrder struct {
+	OrderID     string
+	Amount      int
+	Description string
+	Status      string
+}
+
+// fakeProvider simulates a Midtrans-compatible gateway without any network access.
+// Checkouts point to a page served by the app itself, and completing a checkout
+// posts a notification signed with serverKey back to the app, just like Midtrans would.
+type fakeProvider struct {
+	baseURL    string
+	serverKey  string
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	orders map[string]FakeOrder
+}
+
+func NewFakeProvider(baseURL string, serverKey string) *fakeProvider {
+	return &fakeProvider{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		serverKey:  serverKey,
+		httpClient: &http.Client{},
+		orders:     map[string]FakeOrder{},
+	}
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) CheckConfig() error {
+	if p.serverKey == "" {
+		return fmt.Errorf("%w: FAKE_PAYMENT_SERVER_KEY is not set", ErrProviderNotConfigured)
+	}
+
+	return nil
+}
+
+func (p *fakeProvider) CreateCheckout(ctx context.Context, transaction Transaction, customer Customer) (Checkout, error) {
+	p.mu.Lock()
+	p.orders[transaction.OrderID] = FakeOrder{
+		OrderID:     transaction.OrderID,
+		Amount:      transaction.Amount,
+		Description: transaction.Description,
+		Status:      "pending",
+	}
+	p.mu.Unlock()
+
+	return Checkout{URL: p.baseURL + "/dev/checkout/" + url.PathEscape(transaction.OrderID)}, nil
+}
+
+func (p *fakeProvider) ParseNotification(body []byte) (Event, error) {
+	return parseMidtransNotification(body, p.serverKey)
+}
+
+func (p *fakeProvider) GetStatus(ctx context.Context, orderID string) (Event, error) {
+	order, ok := p.GetOrder(orderID)
+	if !ok {
+		return Event{}, ErrTransactionNotFound
+	}
+
+	event := Event{
+		OrderID:     order.OrderID,
+		Status:      midtransStatus(order.Status, "accept"),
+		RawStatus:   order.Status,
+		GrossAmount: fmt.Sprintf("%d.00", order.Amount),
+		Currency:    "IDR",
+		PaymentType: "fake",
+	}
+
+	return event, nil
+}
+
+// ChargeToken succeeds for any card token except FakeDeclinedCardToken, which lets
+// developers exercise failed charges and dunning.
+func (p *fakeProvider) ChargeToken(ctx context.Context, charge TokenCharge) (Event, error) {
+	status := "settlement"
+	if charge.CardToken == FakeDeclinedCardToken {
+		status = "deny"
+	}
+
+	p.mu.Lock()
+	p.orders[charge.OrderID] = FakeOrder{
+		OrderID: charge.OrderID,
+		Amount:  charge.Amount,
+		Status:  status,
+	}
+	p.mu.Unlock()
+
+	event := Event{
+		OrderID:     charge.OrderID,
+		Status:      midtransStatus(status, "accept"),
+		RawStatus:   status,
+		GrossAmount: fmt.Sprintf("%d.00", charge.Amount),
+		Currency:    "IDR",
+		PaymentType: "fake",
+	}
+
+	return event, nil
+}
+
+func (p *fakeProvider) Refund(ctx context.Context, refund Refund) (RefundResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	order, ok := p.orders[refund.OrderID]
+	if !ok {
+		return RefundResult{}, fmt.Errorf("%w: %w", ErrRefundRejected, ErrTransactionNotFound)
+	}
+
+	order.Status = "partial_refund"
+	if refund.Amount >= order.Amount {
+		order.Status = "refund"
+	}
+	p.orders[refund.OrderID] = order
+
+	return RefundResult{Completed: true}, nil
+}
+
+func (p *fakeProvider) GetOrder(orderID string) (FakeOrder, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	order, ok := p.orders[orderID]
+	return order, ok
+}
+
+// Complete settles, denies or expires an order and notifies the app about it.
+func (p *fakeProvider) Complete(ctx context.Context, orderID string, outcome string) error {
+	transactionStatus, statusCode := "", ""
+
+	switch outcome {
+	case FakeOutcomePay:
+		transactionStatus, statusCode = "settlement", "200"
+	case FakeOutcomeFail:
+		transactionStatus, statusCode = "deny", "202"
+	case FakeOutcomeExpire:
+		transactionStatus, statusCode = "expire", "407"
+	default:
+		return fmt.Errorf("unknown outcome %q", outcome)
+	}
+
+	p.mu.Lock()
+	order, ok := p.orders[orderID]
+	if ok {
+		order.Status = transactionStatus
+		p.orders[orderID] = order
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		return ErrTransactionNotFound
+	}
+
+	grossAmount := fmt.Sprintf("%d.00", order.Amount)
+
+	notification := midtransNotification{
+		TransactionStatus: transactionStatus,
+		OrderID:           orderID,
+		PaymentType:       "fake",
+		FraudStatus:       "accept",
+		StatusCode:        statusCode,
+		GrossAmount:       grossAmount,
+		Currency:          "IDR",
+		SignatureKey:      midtransSignature(orderID, statusCode, grossAmount, p.serverKey),
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/api/v1/transactions/notification/"+p.Name(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("notification rejected with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package payment
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/midtrans/midtrans-go"
+)
+
+// baseURLClient rewrites requests made by the Midtrans SDK onto another base URL.
+// The SDK derives its URLs from the environment type only, so this is the one
+// place where the host can be swapped.
+type baseURLClient struct {
+	baseURL string
+	envURL  string
+	next    midtrans.HttpClient
+}
+
+func (c *baseURLClient) Call(method string, url string, apiKey *string, options *midtrans.ConfigOptions, body io.Reader, result interface{}) *midtrans.Error {
+	if strings.HasPrefix(url, c.envURL) {
+		url = strings.TrimSuffix(c.baseURL, "/") + strings.TrimPrefix(url, c.envURL)
+	}
+
+	return c.next.Call(method, url, apiKey, options, body, result)
+}
+
+// newContextClient returns the SDK's HTTP client with its requests bound to ctx, so a
+// cancelled request or a passed deadline aborts the call to Midtrans. The SDK builds
+// its requests without a context and drops ConfigOptions.Ctx, hence the transport.
+func newContextClient(ctx context.Context, env midtrans.EnvironmentType) midtrans.HttpClient {
+	return &midtrans.HttpClientImplementation{
+		HttpClient: &http.Client{
+			Timeout:   midtrans.DefaultHttpTimeout,
+			Transport: &contextTransport{ctx: ctx, next: http.DefaultTransport},
+		},
+		Logger: midtrans.GetDefaultLogger(env),
+	}
+}
+
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(req.WithContext(t.ctx))
+}
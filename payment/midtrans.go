@@ -0,0 +1,358 @@
+package payment
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+	"github.com/midtrans/midtrans-go/snap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type midtransProvider struct {
+	serverKey string
+	env       midtrans.EnvironmentType
+	apiURL    string
+}
+
+type midtransNotification struct {
+	TransactionStatus string                       `json:"transaction_status"`
+	OrderID           string                       `json:"order_id"`
+	PaymentType       string                       `json:"payment_type"`
+	FraudStatus       string                       `json:"fraud_status"`
+	StatusCode        string                       `json:"status_code"`
+	GrossAmount       string                       `json:"gross_amount"`
+	Currency          string                       `json:"currency"`
+	SignatureKey      string                       `json:"signature_key"`
+	Refunds           []midtransRefundNotification `json:"refunds"`
+}
+
+type midtransRefundNotification struct {
+	RefundKey    string `json:"refund_key"`
+	RefundAmount string `json:"refund_amount"`
+}
+
+func NewMidtransProvider() *midtransProvider {
+	serverKey := os.Getenv("MIDTRANS_SERVER_KEY")
+	env := midtrans.Sandbox
+
+	if os.Getenv("MIDTRANS_ENV") == "production" {
+		env = midtrans.Production
+	}
+
+	return &midtransProvider{
+		serverKey: serverKey,
+		env:       env,
+		apiURL:    os.Getenv("MIDTRANS_API_URL"),
+	}
+}
+
+func (p *midtransProvider) Name() string {
+	return "midtrans"
+}
+
+func (p *midtransProvider) CheckConfig() error {
+	if p.serverKey == "" {
+		return fmt.Errorf("%w: MIDTRANS_SERVER_KEY is not set", ErrProviderNotConfigured)
+	}
+
+	return nil
+}
+
+func (p *midtransProvider) CreateCheckout(ctx context.Context, transaction Transaction, customer Customer) (Checkout, error) {
+	var snapClient snap.Client
+	snapClient.New(p.serverKey, p.env)
+	snapClient.HttpClient = newContextClient(ctx, p.env)
+
+	snapReq := &snap.Request{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  transaction.OrderID,
+			GrossAmt: int64(transaction.Amount),
+		},
+		CustomerDetail: &midtrans.CustomerDetails{
+			FName: customer.Name,
+			Email: customer.Email,
+		},
+		Callbacks: &snap.Callbacks{
+			Finish: os.Getenv("FRONTEND_URL") + "/fund-success",
+		},
+	}
+
+	if transaction.ExpiresAt != nil {
+		snapReq.Expiry = &snap.ExpiryDetails{
+			StartTime: transaction.CreatedAt.Format("2006-01-02 15:04:05 -0700"),
+			Unit:      "minute",
+			Duration:  expiryMinutes(transaction.ExpiresAt.Sub(transaction.CreatedAt)),
+		}
+	}
+
+	_, span := startMidtransSpan(ctx, "snap.CreateTransaction", transaction.OrderID)
+	snapResp, err := snapClient.CreateTransaction(snapReq)
+	endMidtransSpan(span, err)
+	if err != nil {
+		return Checkout{}, err
+	}
+	return Checkout{URL: snapResp.RedirectURL}, nil
+}
+
+func (p *midtransProvider) ParseNotification(body []byte) (Event, error) {
+	return parseMidtransNotification(body, p.serverKey)
+}
+
+func (p *midtransProvider) GetStatus(ctx context.Context, orderID string) (Event, error) {
+	coreClient := p.newCoreClient(ctx)
+
+	_, span := startMidtransSpan(ctx, "coreapi.CheckTransaction", orderID)
+	statusResp, err := coreClient.CheckTransaction(orderID)
+	endMidtransSpan(span, err)
+	if err != nil {
+		if err.GetStatusCode() == http.StatusNotFound {
+			return Event{}, ErrTransactionNotFound
+		}
+		return Event{}, err
+	}
+
+	if statusResp.StatusCode == strconv.Itoa(http.StatusNotFound) {
+		return Event{}, ErrTransactionNotFound
+	}
+
+	event := Event{
+		OrderID:     statusResp.OrderID,
+		Status:      midtransStatus(statusResp.TransactionStatus, statusResp.FraudStatus),
+		RawStatus:   statusResp.TransactionStatus,
+		GrossAmount: statusResp.GrossAmount,
+		Currency:    statusResp.Currency,
+		PaymentType: statusResp.PaymentType,
+	}
+
+	for _, refund := range statusResp.Refunds {
+		event.Refunds = append(event.Refunds, RefundEvent{
+			RefundKey: refund.RefundKey,
+			Amount:    refund.RefundAmount,
+		})
+	}
+
+	return event, nil
+}
+
+func (p *midtransProvider) ChargeToken(ctx context.Context, charge TokenCharge) (Event, error) {
+	coreClient := p.newCoreClient(ctx)
+
+	chargeReq := &coreapi.ChargeReq{
+		PaymentType: coreapi.PaymentTypeCreditCard,
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  charge.OrderID,
+			GrossAmt: int64(charge.Amount),
+		},
+		CustomerDetails: &midtrans.CustomerDetails{
+			FName: charge.Customer.Name,
+			Email: charge.Customer.Email,
+		},
+		CreditCard: &coreapi.CreditCardDetails{
+			TokenID: charge.CardToken,
+		},
+	}
+
+	_, span := startMidtransSpan(ctx, "coreapi.ChargeTransaction", charge.OrderID)
+	chargeResp, err := coreClient.ChargeTransaction(chargeReq)
+	endMidtransSpan(span, err)
+	if err != nil {
+		// Timeouts, rate limits, server and network errors leave the outcome unknown
+		if isDefinitiveRejection(err.GetStatusCode()) {
+			return Event{}, fmt.Errorf("%w: %s", ErrChargeRejected, err.GetMessage())
+		}
+		return Event{}, err
+	}
+
+	event := Event{
+		OrderID:     chargeResp.OrderID,
+		Status:      midtransStatus(chargeResp.TransactionStatus, chargeResp.FraudStatus),
+		RawStatus:   chargeResp.TransactionStatus,
+		GrossAmount: chargeResp.GrossAmount,
+		PaymentType: chargeResp.PaymentType,
+	}
+
+	if chargeResp.StatusCode != "200" && chargeResp.StatusCode != "201" {
+		event.Status = StatusFailed
+	}
+
+	return event, nil
+}
+
+func (p *midtransProvider) Refund(ctx context.Context, refund Refund) (RefundResult, error) {
+	coreClient := p.newCoreClient(ctx)
+
+	refundReq := &coreapi.RefundReq{
+		RefundKey: refund.RefundKey,
+		Amount:    int64(refund.Amount),
+		Reason:    refund.Reason,
+	}
+
+	_, span := startMidtransSpan(ctx, "coreapi.RefundTransaction", refund.OrderID)
+	refundResp, err := coreClient.RefundTransaction(refund.OrderID, refundReq)
+	endMidtransSpan(span, err)
+	if err != nil {
+		// Timeouts, rate limits and server errors leave the outcome unknown
+		if isDefinitiveRejection(err.GetStatusCode()) {
+			return RefundResult{}, fmt.Errorf("%w: %s", ErrRefundRejected, err.GetMessage())
+		}
+		return RefundResult{}, err
+	}
+
+	switch refundResp.StatusCode {
+	case "200":
+		return RefundResult{Completed: true}, nil
+	case "201":
+		return RefundResult{Completed: false}, nil
+	}
+
+	statusCode, _ := strconv.Atoi(refundResp.StatusCode)
+	if isDefinitiveRejection(statusCode) {
+		return RefundResult{}, fmt.Errorf("%w: %s %s", ErrRefundRejected, refundResp.StatusCode, refundResp.StatusMessage)
+	}
+
+	return RefundResult{}, fmt.Errorf("unexpected refund response: %s %s", refundResp.StatusCode, refundResp.StatusMessage)
+}
+
+// expiryMinutes converts a payment window to the whole minutes Snap takes, rounding
+// up so the window never closes at Snap before it does here.
+func expiryMinutes(window time.Duration) int64 {
+	minutes := int64((window + time.Minute - 1) / time.Minute)
+	if minutes < 1 {
+		return 1
+	}
+
+	return minutes
+}
+
+// isDefinitiveRejection tells whether Midtrans turned a request down for good, as
+// opposed to failing in a way where it may still have been carried out.
+func isDefinitiveRejection(statusCode int) bool {
+	return statusCode >= 400 && statusCode < 500 && statusCode != http.StatusRequestTimeout && statusCode != http.StatusTooManyRequests
+}
+
+// newCoreClient builds a Core API client whose calls run under ctx, pointing it at
+// MIDTRANS_API_URL when set so the status checks can run against a local stub of
+// the Midtrans API.
+func (p *midtransProvider) newCoreClient(ctx context.Context) coreapi.Client {
+	var coreClient coreapi.Client
+	coreClient.New(p.serverKey, p.env)
+	coreClient.HttpClient = newContextClient(ctx, p.env)
+
+	if p.apiURL != "" {
+		coreClient.HttpClient = &baseURLClient{
+			baseURL: p.apiURL,
+			envURL:  p.env.BaseUrl(),
+			next:    coreClient.HttpClient,
+		}
+	}
+
+	return coreClient
+}
+
+// startMidtransSpan starts a client span for a call to the Midtrans API.
+func startMidtransSpan(ctx context.Context, operation string, orderID string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "midtrans "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("payment.provider", "midtrans"),
+			attribute.String("payment.order_id", orderID),
+		),
+	)
+}
+
+// endMidtransSpan ends a span started by startMidtransSpan. It takes the SDK's
+// *midtrans.Error as is, since a nil one wrapped in an error interface isn't nil.
+func endMidtransSpan(span trace.Span, err *midtrans.Error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Message)
+		if err.StatusCode != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", err.StatusCode))
+		}
+	}
+
+	span.End()
+}
+
+// midtransStatus maps Midtrans' transaction_status and fraud_status onto our statuses.
+// A captured card payment only counts as paid once the fraud check accepted it.
+func midtransStatus(transactionStatus string, fraudStatus string) string {
+	switch transactionStatus {
+	case "capture":
+		if fraudStatus == "accept" {
+			return StatusPaid
+		}
+		if fraudStatus == "deny" {
+			return StatusFailed
+		}
+		return StatusPending
+	case "settlement":
+		return StatusPaid
+	case "deny", "cancel", "failure":
+		return StatusFailed
+	case "expire":
+		return StatusExpired
+	case "refund":
+		return StatusRefunded
+	case "partial_refund":
+		return StatusPartiallyRefunded
+	}
+
+	return StatusPending
+}
+
+// parseMidtransNotification checks a notification's SHA-512 signature against the
+// server key and translates it into an Event.
+func parseMidtransNotification(body []byte, serverKey string) (Event, error) {
+	var notification midtransNotification
+
+	err := json.Unmarshal(body, &notification)
+	if err != nil {
+		return Event{}, fmt.Errorf("%w: %v", ErrInvalidNotification, err)
+	}
+
+	expectedSignature := midtransSignature(notification.OrderID, notification.StatusCode, notification.GrossAmount, serverKey)
+	if expectedSignature != notification.SignatureKey {
+		return Event{}, ErrInvalidSignature
+	}
+
+	event := Event{
+		OrderID:     notification.OrderID,
+		Status:      midtransStatus(notification.TransactionStatus, notification.FraudStatus),
+		RawStatus:   notification.TransactionStatus,
+		GrossAmount: notification.GrossAmount,
+		Currency:    notification.Currency,
+		PaymentType: notification.PaymentType,
+	}
+
+	// Midtrans only reports a successful payment with status code 200
+	if event.Status == StatusPaid && notification.StatusCode != "200" {
+		event.Warnings = append(event.Warnings, fmt.Sprintf("status code %s does not match transaction status %s", notification.StatusCode, notification.TransactionStatus))
+	}
+
+	for _, refund := range notification.Refunds {
+		event.Refunds = append(event.Refunds, RefundEvent{
+			RefundKey: refund.RefundKey,
+			Amount:    refund.RefundAmount,
+		})
+	}
+
+	return event, nil
+}
+
+func midtransSignature(orderID, statusCode, grossAmount, serverKey string) string {
+	payload := orderID + statusCode + grossAmount + serverKey
+	hash := sha512.Sum512([]byte(payload))
+	return hex.EncodeToString(hash[:])
+}
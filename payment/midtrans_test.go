@@ -0,0 +1,25 @@
+package payment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpiryMinutes(t *testing.T) {
+	tests := []struct {
+		window time.Duration
+		want   int64
+	}{
+		{24 * time.Hour, 1440},
+		{90 * time.Second, 2},
+		{30 * time.Second, 1},
+		{time.Minute, 1},
+		{0, 1},
+	}
+
+	for _, test := range tests {
+		if got := expiryMinutes(test.window); got != test.want {
+			t.Errorf("expiryMinutes(%s) = %d, want %d", test.window, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+)
+
+// Provider is a payment gateway. Each implementation translates its own API and
+// notification format into the types of this package.
+type Provider interface {
+	Name() string
+	CreateCheckout(ctx context.Context, transaction Transaction, customer Customer) (Checkout, error)
+	// ParseNotification verifies the notification's authenticity before parsing it.
+	ParseNotification(body []byte) (Event, error)
+	GetStatus(ctx context.Context, orderID string) (Event, error)
+	Refund(ctx context.Context, refund Refund) (RefundResult, error)
+}
+
+// RecurringProvider is implemented by providers that can charge a saved card token,
+// which recurring pledges depend on.
+type RecurringProvider interface {
+	ChargeToken(ctx context.Context, charge TokenCharge) (Event, error)
+}
+
+// ConfigChecker is implemented by providers that need settings, such as API keys,
+// to work. CheckConfig reports what is missing.
+type ConfigChecker interface {
+	CheckConfig() error
+}
+
+// Registry holds the available providers by name. An empty name selects the default.
+type Registry struct {
+	providers       map[string]Provider
+	defaultProvider string
+}
+
+func NewRegistry(defaultProvider string, providers ...Provider) *Registry {
+	registry := &Registry{
+		providers:       map[string]Provider{},
+		defaultProvider: defaultProvider,
+	}
+
+	for _, provider := range providers {
+		registry.Register(provider)
+	}
+
+	return registry
+}
+
+func (r *Registry) Register(provider Provider) {
+	r.providers[provider.Name()] = provider
+}
+
+func (r *Registry) Default() string {
+	return r.defaultProvider
+}
+
+func (r *Registry) Get(name string) (Provider, error) {
+	if name == "" {
+		name = r.defaultProvider
+	}
+
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+	}
+
+	return provider, nil
+}
@@ -1,73 +1,207 @@
 package payment
 
 import (
-	"backer/user"
-	"crypto/sha512"
-	"encoding/hex"
-	"os"
-	"strconv"
-
-	"github.com/midtrans/midtrans-go"
-	"github.com/midtrans/midtrans-go/snap"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
 )
 
-type service struct {
-	serverKey string
-	env       midtrans.EnvironmentType
-}
+// Custom errors
+var (
+	ErrUnknownProvider       = errors.New("unknown payment provider")
+	ErrInvalidSignature      = errors.New("invalid signature")
+	ErrInvalidNotification   = errors.New("invalid notification")
+	ErrTransactionNotFound   = errors.New("transaction not found at payment provider")
+	ErrRecurringNotSupported = errors.New("payment provider does not support recurring charges")
+	ErrProviderNotConfigured = errors.New("payment provider is not configured")
+	ErrRefundRejected        = errors.New("refund rejected by payment provider")
+	ErrChargeRejected        = errors.New("charge rejected by payment provider")
+)
+
+// Normalized payment statuses reported by every provider
+const (
+	StatusPending           = "pending"
+	StatusPaid              = "paid"
+	StatusFailed            = "failed"
+	StatusExpired           = "expired"
+	StatusRefunded          = "refunded"
+	StatusPartiallyRefunded = "partially_refunded"
+)
+
+var tracer = otel.Tracer("backer/payment")
 
 type Service interface {
-	GetPaymentURL(transaction Transaction, user user.User) (string, error)
-	VerifySignature(orderID, statusCode, grossAmount, signatureKey string) bool
+	DefaultProvider() string
+	CreateCheckout(ctx context.Context, provider string, transaction Transaction, customer Customer) (Checkout, error)
+	ParseNotification(provider string, body []byte) (Event, error)
+	GetStatus(ctx context.Context, provider string, orderID string) (Event, error)
+	Refund(ctx context.Context, provider string, refund Refund) (RefundResult, error)
+	ChargeToken(ctx context.Context, provider string, charge TokenCharge) (Event, error)
+	CheckConfig() error
 }
 
+type service struct {
+	registry *Registry
+}
+
+// Transaction is what a provider needs to know about an order to charge for it.
 type Transaction struct {
-	ID     int
-	Amount int
+	OrderID     string
+	Amount      int
+	Currency    string
+	Description string
+	CreatedAt   time.Time
+	ExpiresAt   *time.Time
+}
+
+type Customer struct {
+	Name  string
+	Email string
+}
+
+// Checkout is where the backer is sent to complete the payment.
+type Checkout struct {
+	URL string
+}
+
+// Event is a provider notification or status check translated into our terms.
+type Event struct {
+	Provider    string
+	OrderID     string
+	Status      string
+	RawStatus   string
+	GrossAmount string
+	Currency    string
+	PaymentType string
+	Refunds     []RefundEvent
+	// Warnings lists inconsistencies the provider found within the notification
+	// itself, e.g. a status code that does not fit the transaction status.
+	Warnings []string
+}
+
+type RefundEvent struct {
+	RefundKey string
+	Amount    string
+}
+
+// Refund asks the provider to return part or all of an order's amount.
+// RefundKey makes the request idempotent at the provider.
+type Refund struct {
+	OrderID   string
+	RefundKey string
+	Amount    int
+	Reason    string
+}
+
+// RefundResult tells whether the provider completed the refund right away or
+// will confirm it later through a notification.
+type RefundResult struct {
+	Completed bool
 }
 
-func (s *service) VerifySignature(orderID, statusCode, grossAmount, signatureKey string) bool {
-	payload := orderID + statusCode + grossAmount + s.serverKey
-	hash := sha512.Sum512([]byte(payload))
-	expectedSignature := hex.EncodeToString(hash[:])
-	return expectedSignature == signatureKey
+// TokenCharge charges a card saved at the provider, without the backer being present.
+type TokenCharge struct {
+	OrderID   string
+	Amount    int
+	CardToken string
+	Customer  Customer
 }
 
-func NewService() *service {
-	serverKey := os.Getenv("MIDTRANS_SERVER_KEY")
-	env := midtrans.Sandbox
+func NewService(registry *Registry) *service {
+	return &service{registry}
+}
 
-	if os.Getenv("MIDTRANS_ENV") == "production" {
-		env = midtrans.Production
+func (s *service) DefaultProvider() string {
+	return s.registry.Default()
+}
+
+func (s *service) CreateCheckout(ctx context.Context, provider string, transaction Transaction, customer Customer) (Checkout, error) {
+	p, err := s.registry.Get(provider)
+	if err != nil {
+		return Checkout{}, err
 	}
 
-	return &service{
-		serverKey: serverKey,
-		env:       env,
+	return p.CreateCheckout(ctx, transaction, customer)
+}
+
+func (s *service) ParseNotification(provider string, body []byte) (Event, error) {
+	p, err := s.registry.Get(provider)
+	if err != nil {
+		return Event{}, err
 	}
+
+	event, err := p.ParseNotification(body)
+	if err != nil {
+		return event, err
+	}
+
+	event.Provider = p.Name()
+	return event, nil
 }
 
-func (s *service) GetPaymentURL(transaction Transaction, user user.User) (string, error) {
-	var snapClient snap.Client
-	snapClient.New(s.serverKey, s.env)
-
-	snapReq := &snap.Request{
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  strconv.Itoa(transaction.ID),
-			GrossAmt: int64(transaction.Amount),
-		},
-		CustomerDetail: &midtrans.CustomerDetails{
-			FName: user.Name,
-			Email: user.Email,
-		},
-		Callbacks: &snap.Callbacks{
-			Finish: os.Getenv("FRONTEND_URL") + "/fund-success",
-		},
+func (s *service) GetStatus(ctx context.Context, provider string, orderID string) (Event, error) {
+	p, err := s.registry.Get(provider)
+	if err != nil {
+		return Event{}, err
 	}
 
-	snapResp, err := snapClient.CreateTransaction(snapReq)
+	event, err := p.GetStatus(ctx, orderID)
 	if err != nil {
-		return "", err
+		return event, err
 	}
-	return snapResp.RedirectURL, nil
+
+	event.Provider = p.Name()
+	return event, nil
+}
+
+func (s *service) Refund(ctx context.Context, provider string, refund Refund) (RefundResult, error) {
+	p, err := s.registry.Get(provider)
+	if err != nil {
+		return RefundResult{}, err
+	}
+
+	return p.Refund(ctx, refund)
+}
+
+func (s *service) ChargeToken(ctx context.Context, provider string, charge TokenCharge) (Event, error) {
+	p, err := s.registry.Get(provider)
+	if err != nil {
+		return Event{}, err
+	}
+
+	recurringProvider, ok := p.(RecurringProvider)
+	if !ok {
+		return Event{}, fmt.Errorf("%w: %q", ErrRecurringNotSupported, p.Name())
+	}
+
+	event, err := recurringProvider.ChargeToken(ctx, charge)
+	if err != nil {
+		return event, err
+	}
+
+	event.Provider = p.Name()
+	return event, nil
+}
+
+// CheckConfig verifies that the default provider exists and has the settings it
+// needs to take payments.
+func (s *service) CheckConfig() error {
+	provider, err := s.registry.Get("")
+	if err != nil {
+		return err
+	}
+
+	checker, ok := provider.(ConfigChecker)
+	if !ok {
+		return nil
+	}
+
+	if err := checker.CheckConfig(); err != nil {
+		return fmt.Errorf("%s: %w", provider.Name(), err)
+	}
+
+	return nil
 }
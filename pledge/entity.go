@@ -0,0 +1,36 @@
+package pledge
+
+import (
+	"backer/campaign"
+	"backer/user"
+	"time"
+)
+
+// Pledge is a recurring donation. PendingTransactionID is the charge still pending
+// at the provider, 0 if there is none.
+type Pledge struct {
+	ID                   int
+	UserID               int
+	CampaignID           int
+	Amount               int
+	Interval             string
+	Status               string
+	Provider             string
+	CardToken            string
+	NextChargeAt         time.Time
+	LastChargedAt        *time.Time
+	FailedAttempts       int
+	LastFailureReason    string
+	PendingTransactionID int
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	User                 user.User
+	Campaign             campaign.Campaign
+}
+
+// ChargeOutcome is a pledge whose pending charge has since been settled, with the
+// status its transaction ended up in.
+type ChargeOutcome struct {
+	Pledge
+	TransactionStatus string
+}
@@ -0,0 +1,45 @@
+package pledge
+
+import "backer/helper"
+
+type PledgeFormatter struct {
+	ID                int    `json:"id"`
+	CampaignID        int    `json:"campaign_id"`
+	CampaignName      string `json:"campaign_name"`
+	Amount            int    `json:"amount"`
+	Interval          string `json:"interval"`
+	Status            string `json:"status"`
+	NextChargeAt      string `json:"next_charge_at"`
+	LastChargedAt     string `json:"last_charged_at"`
+	FailedAttempts    int    `json:"failed_attempts"`
+	LastFailureReason string `json:"last_failure_reason"`
+}
+
+func FormatPledge(pledge Pledge) PledgeFormatter {
+	formatter := PledgeFormatter{}
+	formatter.ID = pledge.ID
+	formatter.CampaignID = pledge.CampaignID
+	formatter.CampaignName = pledge.Campaign.Name
+	formatter.Amount = pledge.Amount
+	formatter.Interval = pledge.Interval
+	formatter.Status = pledge.Status
+	formatter.NextChargeAt = pledge.NextChargeAt.Format(helper.DateTimeFormat)
+	formatter.FailedAttempts = pledge.FailedAttempts
+	formatter.LastFailureReason = pledge.LastFailureReason
+
+	if pledge.LastChargedAt != nil {
+		formatter.LastChargedAt = pledge.LastChargedAt.Format(helper.DateTimeFormat)
+	}
+
+	return formatter
+}
+
+func FormatPledges(pledges []Pledge) []PledgeFormatter {
+	pledgesFormatter := []PledgeFormatter{}
+
+	for _, pledge := range pledges {
+		pledgesFormatter = append(pledgesFormatter, FormatPledge(pledge))
+	}
+
+	return pledgesFormatter
+}
@@ -0,0 +1,18 @@
+package pledge
+
+import "backer/user"
+
+type GetPledgeInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
+
+// CreatePledgeInput takes a card token saved at the payment provider by the
+// frontend's card tokenization, which is charged once per interval.
+type CreatePledgeInput struct {
+	CampaignID int    `json:"campaign_id" binding:"required"`
+	Amount     int    `json:"amount" binding:"required,gt=0"`
+	Interval   string `json:"interval" binding:"required,oneof=monthly yearly"`
+	CardToken  string `json:"card_token" binding:"required"`
+	User       user.User
+}
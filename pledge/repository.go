@@ -0,0 +1,112 @@
+package pledge
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	FindByID(ctx context.Context, ID int) (Pledge, error)
+	FindByUserID(ctx context.Context, userID int) ([]Pledge, error)
+	FindDue(ctx context.Context, now time.Time) ([]Pledge, error)
+	Claim(ctx context.Context, pledge Pledge, now time.Time, until time.Time) (bool, error)
+	FindSettledCharges(ctx context.Context) ([]ChargeOutcome, error)
+	Save(ctx context.Context, pledge Pledge) (Pledge, error)
+	Update(ctx context.Context, pledge Pledge) (Pledge, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{db}
+}
+
+func (r *repository) FindByID(ctx context.Context, ID int) (Pledge, error) {
+	var pledge Pledge
+
+	err := r.db.WithContext(ctx).Preload("Campaign").Where("id = ?", ID).Find(&pledge).Error
+	if err != nil {
+		return pledge, err
+	}
+
+	return pledge, nil
+}
+
+func (r *repository) FindByUserID(ctx context.Context, userID int) ([]Pledge, error) {
+	var pledges []Pledge
+
+	err := r.db.WithContext(ctx).Preload("Campaign").Where("user_id = ?", userID).Order("id DESC").Find(&pledges).Error
+	if err != nil {
+		return pledges, err
+	}
+
+	return pledges, nil
+}
+
+// FindDue returns the pledges whose next charge is due. Pledges waiting for the
+// outcome of their last charge are left out.
+func (r *repository) FindDue(ctx context.Context, now time.Time) ([]Pledge, error) {
+	var pledges []Pledge
+
+	err := r.db.WithContext(ctx).Preload("User").Where("status IN ? AND next_charge_at <= ? AND pending_transaction_id = ?", []string{"active", "past_due"}, now, 0).Order("next_charge_at ASC").Find(&pledges).Error
+	if err != nil {
+		return pledges, err
+	}
+
+	return pledges, nil
+}
+
+// Claim moves a due pledge's next charge to until and reports whether it did. Only
+// one caller can claim a pledge that fell due, so two billing workers never charge
+// the same cycle; a worker that dies mid-charge leaves the pledge to be claimed
+// again once until has passed.
+func (r *repository) Claim(ctx context.Context, pledge Pledge, now time.Time, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Pledge{}).
+		Where("id = ? AND status IN ? AND next_charge_at <= ? AND pending_transaction_id = ?", pledge.ID, []string{"active", "past_due"}, now, 0).
+		Update("next_charge_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// FindSettledCharges returns the pledges whose pending charge has been paid, declined
+// or expired since.
+func (r *repository) FindSettledCharges(ctx context.Context) ([]ChargeOutcome, error) {
+	var outcomes []ChargeOutcome
+
+	err := r.db.WithContext(ctx).Table("pledges").
+		Select("pledges.*, transactions.status AS transaction_status").
+		Joins("JOIN transactions ON transactions.id = pledges.pending_transaction_id").
+		Where("pledges.pending_transaction_id <> ? AND transactions.status NOT IN ?", 0, []string{"pending", "review"}).
+		Order("pledges.id ASC").
+		Scan(&outcomes).Error
+	if err != nil {
+		return outcomes, err
+	}
+
+	return outcomes, nil
+}
+
+func (r *repository) Save(ctx context.Context, pledge Pledge) (Pledge, error) {
+	err := r.db.WithContext(ctx).Create(&pledge).Error
+	if err != nil {
+		return pledge, err
+	}
+
+	return pledge, nil
+}
+
+func (r *repository) Update(ctx context.Context, pledge Pledge) (Pledge, error) {
+	err := r.db.WithContext(ctx).Save(&pledge).Error
+	if err != nil {
+		return pledge, err
+	}
+
+	return pledge, nil
+}
@@ -0,0 +1,89 @@
+package pledge
+
+import (
+	"backer/campaign"
+	"backer/database/databasetest"
+	"backer/user"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRepository(t *testing.T) {
+	db := databasetest.Open(t)
+	r := NewRepository(db)
+	ctx := context.Background()
+
+	backer, err := user.NewRepository(db).Save(ctx, user.User{Name: "Ann", Email: "ann@example.com", Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCampaign, err := campaign.NewRepository(db).Save(ctx, campaign.Campaign{UserID: backer.ID, Name: "Clean water", Slug: "clean-water", GoalAmount: 1000000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	newPledge := func(status string, nextChargeAt time.Time) Pledge {
+		t.Helper()
+
+		pledge, err := r.Save(ctx, Pledge{
+			UserID:       backer.ID,
+			CampaignID:   testCampaign.ID,
+			Amount:       50000,
+			Interval:     "monthly",
+			Status:       status,
+			Provider:     "midtrans",
+			CardToken:    "card-token",
+			NextChargeAt: nextChargeAt,
+		})
+		if err != nil {
+			t.Fatalf("saving pledge: %v", err)
+		}
+
+		return pledge
+	}
+
+	overdue := newPledge("past_due", now.Add(-48*time.Hour))
+	due := newPledge("active", now.Add(-time.Hour))
+	newPledge("active", now.Add(time.Hour))
+	newPledge("cancelled", now.Add(-time.Hour))
+
+	duePledges, err := r.FindDue(ctx, now)
+	if err != nil {
+		t.Fatalf("FindDue: %v", err)
+	}
+	if len(duePledges) != 2 || duePledges[0].ID != overdue.ID || duePledges[1].ID != due.ID {
+		t.Fatalf("due pledges = %+v, want %d and %d, oldest first", duePledges, overdue.ID, due.ID)
+	}
+	if duePledges[0].User.Email != backer.Email {
+		t.Errorf("due pledge user = %q, want the backer preloaded", duePledges[0].User.Email)
+	}
+
+	chargedAt := now
+	due.LastChargedAt = &chargedAt
+	due.NextChargeAt = now.AddDate(0, 1, 0)
+	if _, err := r.Update(ctx, due); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	stored, err := r.FindByID(ctx, due.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.LastChargedAt == nil || !stored.NextChargeAt.Equal(due.NextChargeAt) {
+		t.Errorf("stored pledge = %+v, want the charge times saved", stored)
+	}
+	if stored.Campaign.Name != testCampaign.Name {
+		t.Errorf("pledge campaign = %q, want %q preloaded", stored.Campaign.Name, testCampaign.Name)
+	}
+
+	pledges, err := r.FindByUserID(ctx, backer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pledges) != 4 || pledges[0].ID < pledges[3].ID {
+		t.Errorf("FindByUserID returned %d pledges, want 4, newest first", len(pledges))
+	}
+}
@@ -0,0 +1,370 @@
+package pledge
+
+import (
+	"backer/campaign"
+	"backer/logger"
+	"backer/transaction"
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Custom errors
+var (
+	ErrCampaignNotFound    = errors.New("campaign not found")
+	ErrPledgeNotFound      = errors.New("pledge not found")
+	ErrNotAuthorized       = errors.New("not authorized")
+	ErrInvalidStatusChange = errors.New("pledge cannot change to this status")
+	ErrFirstChargeFailed   = errors.New("first charge of the pledge failed")
+	ErrAmountOutOfRange    = transaction.ErrAmountOutOfRange
+)
+
+// Reasons a pledge charge failed, stored on the pledge and shown to the backer. The
+// provider's own error is only logged.
+const (
+	FailureChargeFailed    = "charge_failed"
+	FailurePaymentDeclined = "payment_declined"
+	FailurePaymentExpired  = "payment_expired"
+)
+
+// chargeClaimLease is how long a claimed pledge is kept from other billing workers
+// while its charge runs.
+const chargeClaimLease = 15 * time.Minute
+
+// dunningSchedule is how long to wait before retrying a failed charge, per attempt.
+// A pledge is cancelled once the last retry fails too.
+var dunningSchedule = []time.Duration{
+	24 * time.Hour,
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+var tracer = otel.Tracer("backer/pledge")
+
+type Service interface {
+	GetPledgesByUserID(ctx context.Context, userID int) ([]Pledge, error)
+	CreatePledge(ctx context.Context, input CreatePledgeInput) (Pledge, error)
+	PausePledge(ctx context.Context, input GetPledgeInput) (Pledge, error)
+	ResumePledge(ctx context.Context, input GetPledgeInput) (Pledge, error)
+	CancelPledge(ctx context.Context, input GetPledgeInput) (Pledge, error)
+	ChargeDuePledges(ctx context.Context) (int, error)
+}
+
+type service struct {
+	repository         Repository
+	campaignRepository campaign.Repository
+	transactionService transaction.Service
+}
+
+func NewService(repository Repository, campaignRepository campaign.Repository, transactionService transaction.Service) *service {
+	return &service{repository, campaignRepository, transactionService}
+}
+
+func (s *service) GetPledgesByUserID(ctx context.Context, userID int) ([]Pledge, error) {
+	ctx, span := tracer.Start(ctx, "pledge.GetPledgesByUserID")
+	defer span.End()
+
+	pledges, err := s.repository.FindByUserID(ctx, userID)
+	if err != nil {
+		return pledges, err
+	}
+
+	return pledges, nil
+}
+
+// CreatePledge starts a pledge and charges its first cycle immediately, so a card
+// that cannot be charged is rejected up front.
+func (s *service) CreatePledge(ctx context.Context, input CreatePledgeInput) (Pledge, error) {
+	ctx, span := tracer.Start(ctx, "pledge.CreatePledge")
+	defer span.End()
+
+	campaign, err := s.campaignRepository.FindByID(ctx, input.CampaignID)
+	if err != nil {
+		return Pledge{}, err
+	}
+
+	if campaign.ID == 0 {
+		return Pledge{}, ErrCampaignNotFound
+	}
+
+	if err := transaction.CheckAmount(campaign, input.Amount); err != nil {
+		return Pledge{}, err
+	}
+
+	pledge := Pledge{}
+	pledge.UserID = input.User.ID
+	pledge.CampaignID = input.CampaignID
+	pledge.Amount = input.Amount
+	pledge.Interval = input.Interval
+	pledge.CardToken = input.CardToken
+	pledge.Status = "active"
+	// Claimed from the start, so the billing worker leaves the first charge to us
+	pledge.NextChargeAt = time.Now().Add(chargeClaimLease)
+
+	newPledge, err := s.repository.Save(ctx, pledge)
+	if err != nil {
+		return newPledge, err
+	}
+
+	newPledge.User = input.User
+	newPledge.Campaign = campaign
+
+	newPledge, charged, err := s.chargePledge(ctx, newPledge)
+	if err != nil {
+		return newPledge, err
+	}
+
+	if !charged {
+		newPledge.Status = "cancelled"
+		if _, err := s.repository.Update(ctx, newPledge); err != nil {
+			return newPledge, err
+		}
+		return newPledge, ErrFirstChargeFailed
+	}
+
+	return newPledge, nil
+}
+
+func (s *service) PausePledge(ctx context.Context, input GetPledgeInput) (Pledge, error) {
+	ctx, span := tracer.Start(ctx, "pledge.PausePledge")
+	defer span.End()
+
+	pledge, err := s.findOwnPledge(ctx, input)
+	if err != nil {
+		return pledge, err
+	}
+
+	if pledge.Status != "active" && pledge.Status != "past_due" {
+		return pledge, ErrInvalidStatusChange
+	}
+
+	pledge.Status = "paused"
+
+	return s.repository.Update(ctx, pledge)
+}
+
+// ResumePledge reactivates a paused pledge. A cycle that fell due while paused is
+// charged on the next billing run rather than skipped.
+func (s *service) ResumePledge(ctx context.Context, input GetPledgeInput) (Pledge, error) {
+	ctx, span := tracer.Start(ctx, "pledge.ResumePledge")
+	defer span.End()
+
+	pledge, err := s.findOwnPledge(ctx, input)
+	if err != nil {
+		return pledge, err
+	}
+
+	if pledge.Status != "paused" {
+		return pledge, ErrInvalidStatusChange
+	}
+
+	pledge.Status = "active"
+	pledge.FailedAttempts = 0
+
+	return s.repository.Update(ctx, pledge)
+}
+
+func (s *service) CancelPledge(ctx context.Context, input GetPledgeInput) (Pledge, error) {
+	ctx, span := tracer.Start(ctx, "pledge.CancelPledge")
+	defer span.End()
+
+	pledge, err := s.findOwnPledge(ctx, input)
+	if err != nil {
+		return pledge, err
+	}
+
+	if pledge.Status == "cancelled" {
+		return pledge, ErrInvalidStatusChange
+	}
+
+	pledge.Status = "cancelled"
+
+	return s.repository.Update(ctx, pledge)
+}
+
+// ChargeDuePledges first applies the outcome of charges that were pending at the
+// provider, then bills every pledge whose next charge is due, including dunning
+// retries, and returns how many were charged without failing. Each pledge is
+// claimed before it is charged, so concurrent billing workers never charge the same
+// cycle twice.
+func (s *service) ChargeDuePledges(ctx context.Context) (int, error) {
+	ctx, span := tracer.Start(ctx, "pledge.ChargeDuePledges")
+	defer span.End()
+
+	if err := s.applySettledCharges(ctx); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+
+	pledges, err := s.repository.FindDue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	charged := 0
+
+	for _, pledge := range pledges {
+		// Pledges left over are picked up by the next run
+		if err := ctx.Err(); err != nil {
+			return charged, err
+		}
+
+		claimed, err := s.repository.Claim(ctx, pledge, now, time.Now().Add(chargeClaimLease))
+		if err != nil {
+			return charged, err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		_, ok, err := s.chargePledge(ctx, pledge)
+		if err != nil {
+			return charged, err
+		}
+
+		if ok {
+			charged++
+		}
+	}
+
+	return charged, nil
+}
+
+// chargePledge charges one cycle and schedules the next one. A charge still pending
+// at the provider counts as made until its transaction says otherwise, see
+// applySettledCharges. A failed charge is retried following dunningSchedule before
+// the pledge is cancelled.
+func (s *service) chargePledge(ctx context.Context, pledge Pledge) (Pledge, bool, error) {
+	chargeInput := transaction.ChargeRecurringInput{
+		PledgeID:   pledge.ID,
+		CampaignID: pledge.CampaignID,
+		Amount:     pledge.Amount,
+		Provider:   pledge.Provider,
+		CardToken:  pledge.CardToken,
+		User:       pledge.User,
+	}
+
+	now := time.Now()
+
+	chargedTransaction, chargeErr := s.transactionService.ChargeRecurring(ctx, chargeInput)
+
+	if chargedTransaction.Provider != "" {
+		pledge.Provider = chargedTransaction.Provider
+	}
+
+	charged := false
+
+	switch {
+	case chargeErr != nil:
+		logger.FromContext(ctx).Warn("Pledge charge failed", "pledge_id", pledge.ID, "error", chargeErr)
+		pledge = recordFailure(pledge, FailureChargeFailed, now)
+	case chargedTransaction.Status == "paid" || chargedTransaction.Status == "pending" || chargedTransaction.Status == "review":
+		charged = true
+		pledge.Status = "active"
+		pledge.LastChargedAt = &now
+		pledge.NextChargeAt = nextChargeAt(now, pledge.Interval)
+
+		if chargedTransaction.Status == "paid" {
+			pledge.FailedAttempts = 0
+			pledge.LastFailureReason = ""
+		} else {
+			pledge.PendingTransactionID = chargedTransaction.ID
+		}
+	default:
+		pledge = recordFailure(pledge, failureReason(chargedTransaction.Status), now)
+	}
+
+	// Whatever the charge did is recorded even past the deadline, or the pledge would
+	// be charged again on the next run
+	updatedPledge, err := s.repository.Update(context.WithoutCancel(ctx), pledge)
+	if err != nil {
+		return updatedPledge, charged, err
+	}
+
+	return updatedPledge, charged, nil
+}
+
+// applySettledCharges records the outcome of charges that were pending at the
+// provider once their transaction is paid, declined or expired, so a charge that
+// fails after the fact still goes through dunning.
+func (s *service) applySettledCharges(ctx context.Context) error {
+	outcomes, err := s.repository.FindSettledCharges(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, outcome := range outcomes {
+		pledge := outcome.Pledge
+		pledge.PendingTransactionID = 0
+
+		// A paused or cancelled pledge keeps its status whatever the charge did
+		if pledge.Status == "active" || pledge.Status == "past_due" {
+			if outcome.TransactionStatus == "paid" || outcome.TransactionStatus == "refunded" {
+				pledge.FailedAttempts = 0
+				pledge.LastFailureReason = ""
+			} else {
+				pledge = recordFailure(pledge, failureReason(outcome.TransactionStatus), time.Now())
+			}
+		}
+
+		if _, err := s.repository.Update(ctx, pledge); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordFailure counts a failed charge and schedules the retry, or cancels the pledge
+// once dunningSchedule is used up.
+func recordFailure(pledge Pledge, reason string, now time.Time) Pledge {
+	pledge.FailedAttempts = pledge.FailedAttempts + 1
+	pledge.LastFailureReason = reason
+
+	if pledge.FailedAttempts > len(dunningSchedule) {
+		pledge.Status = "cancelled"
+	} else {
+		pledge.Status = "past_due"
+		pledge.NextChargeAt = now.Add(dunningSchedule[pledge.FailedAttempts-1])
+	}
+
+	return pledge
+}
+
+// failureReason tells why a charge whose transaction ended up in status failed.
+func failureReason(status string) string {
+	if status == "expired" {
+		return FailurePaymentExpired
+	}
+
+	return FailurePaymentDeclined
+}
+
+func (s *service) findOwnPledge(ctx context.Context, input GetPledgeInput) (Pledge, error) {
+	pledge, err := s.repository.FindByID(ctx, input.ID)
+	if err != nil {
+		return pledge, err
+	}
+
+	if pledge.ID == 0 {
+		return pledge, ErrPledgeNotFound
+	}
+
+	if pledge.UserID != input.User.ID {
+		return pledge, ErrNotAuthorized
+	}
+
+	return pledge, nil
+}
+
+func nextChargeAt(from time.Time, interval string) time.Time {
+	if interval == "yearly" {
+		return from.AddDate(1, 0, 0)
+	}
+
+	return from.AddDate(0, 1, 0)
+}
@@ -0,0 +1,143 @@
+package pledge
+
+import (
+	"backer/campaign"
+	"backer/database/databasetest"
+	"backer/transaction"
+	"backer/user"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// stubCharger answers recurring charges with a canned result.
+type stubCharger struct {
+	transaction.Service
+	result transaction.Transaction
+	err    error
+}
+
+func (c *stubCharger) ChargeRecurring(ctx context.Context, input transaction.ChargeRecurringInput) (transaction.Transaction, error) {
+	return c.result, c.err
+}
+
+func newTestService(t *testing.T) (*service, *stubCharger, *gorm.DB, user.User, campaign.Campaign) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+	ctx := context.Background()
+
+	backer, err := user.NewRepository(db).Save(ctx, user.User{Name: "Ann", Email: "ann@example.com", Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCampaign, err := campaign.NewRepository(db).Save(ctx, campaign.Campaign{UserID: backer.ID, Name: "Clean water", Slug: "clean-water", GoalAmount: 1000000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	charger := &stubCharger{}
+
+	return NewService(NewRepository(db), campaign.NewRepository(db), charger), charger, db, backer, testCampaign
+}
+
+func TestCreatePledgeStoresAFixedFailureReason(t *testing.T) {
+	s, charger, _, backer, testCampaign := newTestService(t)
+
+	charger.err = errors.New("midtrans: card 4811-1111 declined by issuer, api key sk-123")
+
+	newPledge, err := s.CreatePledge(context.Background(), CreatePledgeInput{CampaignID: testCampaign.ID, Amount: 50000, Interval: "monthly", CardToken: "card-token", User: backer})
+	if !errors.Is(err, ErrFirstChargeFailed) {
+		t.Fatalf("CreatePledge = %v, want %v", err, ErrFirstChargeFailed)
+	}
+
+	if newPledge.LastFailureReason != FailureChargeFailed || newPledge.Status != "cancelled" {
+		t.Errorf("pledge = %q with reason %q, want cancelled with %q", newPledge.Status, newPledge.LastFailureReason, FailureChargeFailed)
+	}
+}
+
+func TestChargeDuePledgesDunsChargesThatFailLater(t *testing.T) {
+	s, charger, db, backer, testCampaign := newTestService(t)
+	ctx := context.Background()
+
+	pending, err := transaction.NewRepository(db).Save(ctx, transaction.Transaction{CampaignID: testCampaign.ID, UserID: backer.ID, Amount: 50000, Status: "pending", Code: "TRX-1", Provider: "midtrans"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	charger.result = pending
+
+	newPledge, err := s.CreatePledge(ctx, CreatePledgeInput{CampaignID: testCampaign.ID, Amount: 50000, Interval: "monthly", CardToken: "card-token", User: backer})
+	if err != nil {
+		t.Fatalf("CreatePledge: %v", err)
+	}
+	if newPledge.Status != "active" || newPledge.PendingTransactionID != pending.ID {
+		t.Fatalf("pledge = %q waiting for %d, want active waiting for %d", newPledge.Status, newPledge.PendingTransactionID, pending.ID)
+	}
+
+	// Nothing changes while the charge is still pending
+	if _, err := s.ChargeDuePledges(ctx); err != nil {
+		t.Fatalf("ChargeDuePledges: %v", err)
+	}
+
+	stored, err := s.repository.FindByID(ctx, newPledge.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.PendingTransactionID != pending.ID || stored.FailedAttempts != 0 {
+		t.Errorf("pledge = %+v, want it still waiting for its charge", stored)
+	}
+
+	if err := db.Model(&transaction.Transaction{}).Where("id = ?", pending.ID).Update("status", "expired").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ChargeDuePledges(ctx); err != nil {
+		t.Fatalf("ChargeDuePledges: %v", err)
+	}
+
+	stored, err = s.repository.FindByID(ctx, newPledge.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != "past_due" || stored.FailedAttempts != 1 || stored.LastFailureReason != FailurePaymentExpired || stored.PendingTransactionID != 0 {
+		t.Errorf("pledge = %q, %d failed attempts, reason %q, waiting for %d; want past_due, 1, %q, 0",
+			stored.Status, stored.FailedAttempts, stored.LastFailureReason, stored.PendingTransactionID, FailurePaymentExpired)
+	}
+	if until := time.Until(stored.NextChargeAt); until < 23*time.Hour || until > dunningSchedule[0] {
+		t.Errorf("next charge in %s, want the first dunning retry in %s", until, dunningSchedule[0])
+	}
+}
+
+func TestClaim(t *testing.T) {
+	s, _, _, backer, testCampaign := newTestService(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	due, err := s.repository.Save(ctx, Pledge{UserID: backer.ID, CampaignID: testCampaign.ID, Amount: 50000, Interval: "monthly", Status: "active", NextChargeAt: now.Add(-time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err := s.repository.Claim(ctx, due, now, now.Add(chargeClaimLease))
+	if err != nil || !claimed {
+		t.Fatalf("Claim = %v, %v; want true", claimed, err)
+	}
+
+	// A second worker that found the same pledge due loses
+	claimed, err = s.repository.Claim(ctx, due, now, now.Add(chargeClaimLease))
+	if err != nil || claimed {
+		t.Fatalf("claiming again = %v, %v; want false", claimed, err)
+	}
+
+	duePledges, err := s.repository.FindDue(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(duePledges) != 0 {
+		t.Errorf("FindDue returned %d claimed pledges, want none", len(duePledges))
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"backer/config"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"gorm.io/gorm"
+)
+
+// serve runs the HTTP server until SIGINT or SIGTERM. On shutdown it stops taking
+// new connections, lets in-flight requests, the running worker jobs and the
+// receipts being sent finish within SERVER_SHUTDOWN_TIMEOUT, then flushes the
+// buffered spans and closes the database pool.
+func serve(handler http.Handler, db *gorm.DB, stopWorkers context.CancelFunc, workers *sync.WaitGroup, waitForReceipts func(context.Context) bool, shutdownTracing func(context.Context) error) {
+	cfg := config.AppConfig
+
+	server := &http.Server{
+		Addr:              net.JoinHostPort(cfg.ServerHost, cfg.ServerPort),
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+		ReadTimeout:       cfg.ServerReadTimeout,
+		WriteTimeout:      cfg.ServerWriteTimeout,
+		IdleTimeout:       cfg.ServerIdleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Listening", "addr", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		fatal("Server failed", "error", err)
+	case <-ctx.Done():
+	}
+
+	// Restore the default handling so a second signal exits right away
+	stop()
+	slog.Info("Shutting down, waiting for in-flight work", "timeout", cfg.ServerShutdownTimeout.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ServerShutdownTimeout)
+	defer cancel()
+
+	// Workers finish their current job while the server drains
+	stopWorkers()
+
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Warn("Requests still in flight at the shutdown deadline", "error", err)
+	}
+
+	if !waitGroupDone(shutdownCtx, workers) {
+		slog.Warn("Background jobs still running at the shutdown deadline")
+	}
+
+	// Requests have drained, so no more receipts are queued
+	if !waitForReceipts(shutdownCtx) {
+		slog.Warn("Receipts still being sent at the shutdown deadline")
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Warn("Flushing traces failed", "error", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("Closing the database pool failed", "error", err)
+		}
+	}
+
+	slog.Info("Shutdown complete")
+}
+
+// waitGroupDone waits for wg until ctx ends and reports whether wg finished.
+func waitGroupDone(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
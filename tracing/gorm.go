@@ -0,0 +1,13 @@
+package tracing
+
+import (
+	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
+)
+
+// InstrumentDB adds a span for every gorm query to the trace in its context.
+// Query arguments are left out so no personal data ends up in traces, and the
+// query metrics come from the metrics package instead.
+func InstrumentDB(db *gorm.DB) error {
+	return db.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics(), gormtracing.WithoutQueryVariables()))
+}
@@ -0,0 +1,22 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// Middleware starts a server span per request, named after the route template and
+// continuing the trace of an incoming traceparent header. Requests to skipPaths
+// are not traced.
+func Middleware(skipPaths ...string) gin.HandlerFunc {
+	skip := map[string]bool{}
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return otelgin.Middleware(ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return !skip[r.URL.Path]
+	}))
+}
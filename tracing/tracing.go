@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// ServiceName names the app in traces unless OTEL_SERVICE_NAME says otherwise.
+const ServiceName = "backer"
+
+// Custom errors
+var (
+	ErrUnknownExporter = errors.New("unknown tracing exporter, use none, otlp or stdout")
+)
+
+// Setup installs the global tracer provider for the given exporter and returns a
+// function that flushes the spans still buffered. With "none" spans are never
+// recorded. The otlp exporter reads the standard OTEL_EXPORTER_OTLP_* variables.
+func Setup(ctx context.Context, exporterName string, sampleRatio float64, version string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch exporterName {
+	case "none", "":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExporter, exporterName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName), semconv.ServiceVersion(version)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := NewProvider(exporter, sampleRatio, sdktrace.WithResource(res))
+
+	return provider.Shutdown, nil
+}
+
+// NewProvider makes a tracer provider exporting to exporter the global one. Tests
+// pass a tracetest.NewInMemoryExporter and call ForceFlush before reading its spans.
+func NewProvider(exporter sdktrace.SpanExporter, sampleRatio float64, options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	options = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithBatcher(exporter),
+		// Follow the caller's decision so a trace is never cut in half
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	}, options...)
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+
+	return provider
+}
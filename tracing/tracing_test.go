@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"backer/apperror"
+	"backer/campaign"
+	"backer/database/databasetest"
+	"backer/handler"
+	"backer/user"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const (
+	remoteTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	remoteSpanID  = "00f067aa0ba902b7"
+)
+
+func TestRequestTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider(exporter, 1)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	db := databasetest.Open(t)
+	if err := InstrumentDB(db); err != nil {
+		t.Fatalf("InstrumentDB: %v", err)
+	}
+
+	owner, err := user.NewRepository(db).Save(context.Background(), user.User{Name: "Ann", Email: "ann@example.com", Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCampaign, err := campaign.NewRepository(db).Save(context.Background(), campaign.Campaign{UserID: owner.ID, Name: "Clean water", Slug: "clean-water", GoalAmount: 1000000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware("/healthz"), apperror.Middleware())
+	router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/v1/campaigns/:id", handler.NewCampaignHandler(campaign.NewService(campaign.NewRepository(db))).GetCampaign)
+
+	// Setup queries are not part of any request
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	exporter.Reset()
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/campaigns/"+strconv.Itoa(testCampaign.ID), nil)
+	request.Header.Set("traceparent", "00-"+remoteTraceID+"-"+remoteSpanID+"-01")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET campaign = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.GetSpans()
+	byName := map[string]tracetest.SpanStub{}
+	for _, span := range spans {
+		if span.SpanContext.TraceID().String() != remoteTraceID {
+			t.Errorf("span %q is in trace %s, want the caller's trace %s", span.Name, span.SpanContext.TraceID(), remoteTraceID)
+		}
+		byName[span.Name] = span
+	}
+
+	server, ok := byName["GET /api/v1/campaigns/:id"]
+	if !ok {
+		t.Fatalf("no server span named after the route among %v", spanNames(spans))
+	}
+	if server.Parent.SpanID().String() != remoteSpanID || !server.Parent.IsRemote() {
+		t.Errorf("server span parent = %s, want the caller's span %s", server.Parent.SpanID(), remoteSpanID)
+	}
+
+	// Preloads run inside the query that loads the campaign. The health check is
+	// not traced at all.
+	wantParents := map[string]string{
+		"campaign.GetCampaignByID": "GET /api/v1/campaigns/:id",
+		"select campaigns":         "campaign.GetCampaignByID",
+		"select campaign_images":   "select campaigns",
+		"select users":             "select campaigns",
+	}
+	if len(spans) != len(wantParents)+1 {
+		t.Errorf("recorded spans %v, want the server span and %d children", spanNames(spans), len(wantParents))
+	}
+
+	for name, parentName := range wantParents {
+		span, ok := byName[name]
+		if !ok {
+			t.Errorf("no %q span among %v", name, spanNames(spans))
+			continue
+		}
+
+		if span.Parent.SpanID() != byName[parentName].SpanContext.SpanID() {
+			t.Errorf("parent of %q is not %q", name, parentName)
+		}
+	}
+}
+
+func spanNames(spans tracetest.SpanStubs) []string {
+	var names []string
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+
+	return names
+}
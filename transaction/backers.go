@@ -0,0 +1,86 @@
+package transaction
+
+import "context"
+
+// Pagination defaults of the public backer endpoints
+const (
+	defaultBackersLimit     = 20
+	defaultLeaderboardLimit = 10
+)
+
+// BackerTotal is one row of a campaign leaderboard, aggregated by the repository.
+type BackerTotal struct {
+	UserID         int
+	IsAnonymous    bool
+	Name           string
+	AvatarFileName string
+	TotalAmount    int
+	BackingCount   int
+}
+
+// GetCampaignBackers returns one page of a campaign's paid transactions, newest
+// first, along with the total number of paid transactions.
+func (s *service) GetCampaignBackers(ctx context.Context, input GetCampaignBackersInput) ([]Transaction, int64, error) {
+	ctx, span := tracer.Start(ctx, "transaction.GetCampaignBackers")
+	defer span.End()
+
+	campaign, err := s.campaignRepository.FindByID(ctx, input.ID)
+	if err != nil {
+		return []Transaction{}, 0, err
+	}
+
+	if campaign.ID == 0 {
+		return []Transaction{}, 0, ErrCampaignNotFound
+	}
+
+	page, limit := BackersPage(input)
+
+	transactions, total, err := s.repository.GetBackersByCampaignID(ctx, input.ID, limit, (page-1)*limit)
+	if err != nil {
+		return transactions, total, err
+	}
+
+	return transactions, total, nil
+}
+
+// GetLeaderboard returns a campaign's top contributors by total amount given.
+func (s *service) GetLeaderboard(ctx context.Context, input GetLeaderboardInput) ([]BackerTotal, error) {
+	ctx, span := tracer.Start(ctx, "transaction.GetLeaderboard")
+	defer span.End()
+
+	campaign, err := s.campaignRepository.FindByID(ctx, input.ID)
+	if err != nil {
+		return []BackerTotal{}, err
+	}
+
+	if campaign.ID == 0 {
+		return []BackerTotal{}, ErrCampaignNotFound
+	}
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultLeaderboardLimit
+	}
+
+	totals, err := s.repository.GetTopBackersByCampaignID(ctx, input.ID, limit)
+	if err != nil {
+		return totals, err
+	}
+
+	return totals, nil
+}
+
+// BackersPage resolves the page and page size requested by input.
+func BackersPage(input GetCampaignBackersInput) (int, int) {
+	page, limit := input.Page, input.Limit
+
+	if page == 0 {
+		page = 1
+	}
+
+	if limit == 0 {
+		limit = defaultBackersLimit
+	}
+
+	return page, limit
+}
@@ -7,15 +7,69 @@ import (
 )
 
 type Transaction struct {
-	ID         int
-	CampaignID int
-	UserID     int
-	Amount     int
-	Status     string
-	Code       string
-	PaymentURL string
-	User       user.User
-	Campaign   campaign.Campaign
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID                  int
+	CampaignID          int
+	UserID              int
+	Amount              int
+	Status              string
+	Code                string `gorm:"uniqueIndex;size:64"`
+	PaymentURL          string
+	Provider            string
+	PledgeID            int
+	Perk                string
+	IsAnonymous         bool
+	Message             string
+	MessageStatus       string
+	GuestName           string
+	GuestEmail          string `gorm:"index;size:255"`
+	ClaimTokenHash      string `gorm:"index;size:64"`
+	ClaimTokenExpiresAt *time.Time
+	ExpiresAt           *time.Time
+	PaidAt              *time.Time
+	RefundedAmount      int
+	User                user.User
+	Campaign            campaign.Campaign
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// Receipt is the numbered receipt issued for a paid transaction. The PDF itself is
+// stored under config.AppConfig.ReceiptDir.
+type Receipt struct {
+	ID            int
+	TransactionID int    `gorm:"uniqueIndex"`
+	Number        string `gorm:"uniqueIndex;size:64"`
+	FileName      string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// FraudAlert records a payment notification that disagreed with the stored
+// transaction. The transaction waits in "review" until an admin resolves it.
+type FraudAlert struct {
+	ID             int
+	TransactionID  int `gorm:"index"`
+	Kind           string
+	Expected       string
+	Received       string
+	Status         string
+	Resolution     string
+	ResolvedBy     int
+	ResolutionNote string
+	Transaction    Transaction
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type Refund struct {
+	ID            int
+	TransactionID int
+	RequestedBy   int
+	Amount        int
+	Reason        string
+	Note          string
+	Status        string
+	RefundKey     string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
@@ -0,0 +1,237 @@
+package transaction
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+// exportBatchSize is how many transactions an export loads from the database at once.
+const exportBatchSize = 500
+
+var exportHeader = []string{
+	"Transaction ID",
+	"Code",
+	"Created At",
+	"Updated At",
+	"Status",
+	"Amount",
+	"Refunded Amount",
+	"Reward Tier",
+	"Backer Name",
+	"Backer Email",
+	"Backer Type",
+	"Anonymous",
+	"Message",
+}
+
+// ExportFilter selects the transactions of an export. Until is exclusive.
+type ExportFilter struct {
+	CampaignID int
+	Status     string
+	From       time.Time
+	Until      time.Time
+}
+
+// Export is an authorized export of a campaign's transactions, written out by Write.
+type Export struct {
+	repository Repository
+	filter     ExportFilter
+	format     string
+	fileName   string
+}
+
+// ExportCampaignTransactions prepares the export of a campaign's transactions for
+// its owner. Nothing is read until the export is written.
+func (s *service) ExportCampaignTransactions(ctx context.Context, input ExportCampaignTransactionsInput) (Export, error) {
+	ctx, span := tracer.Start(ctx, "transaction.ExportCampaignTransactions")
+	defer span.End()
+
+	campaign, err := s.campaignRepository.FindByID(ctx, input.ID)
+	if err != nil {
+		return Export{}, err
+	}
+
+	if campaign.ID == 0 {
+		return Export{}, ErrCampaignNotFound
+	}
+
+	if campaign.UserID != input.User.ID {
+		return Export{}, ErrNotAuthorized
+	}
+
+	format := input.Format
+	if format == "" {
+		format = ExportFormatCSV
+	}
+
+	filter := ExportFilter{
+		CampaignID: campaign.ID,
+		Status:     input.Status,
+		From:       input.From,
+	}
+
+	if !input.To.IsZero() {
+		filter.Until = input.To.AddDate(0, 0, 1)
+	}
+
+	export := Export{
+		repository: s.repository,
+		filter:     filter,
+		format:     format,
+		fileName:   fmt.Sprintf("%s-transactions-%s.%s", campaign.Slug, time.Now().Format("20060102"), format),
+	}
+
+	return export, nil
+}
+
+func (e Export) FileName() string {
+	return e.fileName
+}
+
+func (e Export) ContentType() string {
+	if e.format == ExportFormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+// Write streams the export to w.
+func (e Export) Write(ctx context.Context, w io.Writer) error {
+	if e.format == ExportFormatXLSX {
+		return e.writeXLSX(ctx, w)
+	}
+
+	return e.writeCSV(ctx, w)
+}
+
+func (e Export) writeCSV(ctx context.Context, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(exportHeader); err != nil {
+		return err
+	}
+
+	err := e.repository.EachByCampaignID(ctx, e.filter, func(transactions []Transaction) error {
+		for _, transaction := range transactions {
+			var record []string
+			for _, value := range exportRow(transaction) {
+				record = append(record, fmt.Sprint(value))
+			}
+
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeXLSX uses excelize's stream writer, which keeps rows on disk rather than in
+// memory until the workbook is written.
+func (e Export) writeXLSX(ctx context.Context, w io.Writer) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	streamWriter, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, len(exportHeader))
+	for i, title := range exportHeader {
+		header[i] = title
+	}
+
+	if err := streamWriter.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	row := 1
+
+	err = e.repository.EachByCampaignID(ctx, e.filter, func(transactions []Transaction) error {
+		for _, transaction := range transactions {
+			row++
+
+			cell, err := excelize.CoordinatesToCellName(1, row)
+			if err != nil {
+				return err
+			}
+
+			if err := streamWriter.SetRow(cell, exportRow(transaction)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := streamWriter.Flush(); err != nil {
+		return err
+	}
+
+	return file.Write(w)
+}
+
+// exportRow lays out one transaction following exportHeader. Anonymous backers stay
+// anonymous to the campaign owner, as everywhere else.
+func exportRow(transaction Transaction) []interface{} {
+	name, email := donorOf(transaction)
+	if transaction.IsAnonymous {
+		name, email = AnonymousBackerName, ""
+	}
+
+	backerType := "registered"
+	if transaction.UserID == 0 {
+		backerType = "guest"
+	}
+
+	return []interface{}{
+		transaction.ID,
+		transaction.Code,
+		transaction.CreatedAt.Format(time.RFC3339),
+		transaction.UpdatedAt.Format(time.RFC3339),
+		transaction.Status,
+		transaction.Amount,
+		transaction.RefundedAmount,
+		spreadsheetSafe(transaction.Perk),
+		spreadsheetSafe(name),
+		spreadsheetSafe(email),
+		backerType,
+		transaction.IsAnonymous,
+		spreadsheetSafe(transaction.Message),
+	}
+}
+
+// spreadsheetSafe keeps text typed by backers from being run as a formula when the
+// export is opened in a spreadsheet.
+func spreadsheetSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
@@ -18,7 +18,7 @@ type CampaignTransactionFormatter struct {
 func FormatCampaignTransaction(transaction Transaction) CampaignTransactionFormatter {
 	formatter := CampaignTransactionFormatter{}
 	formatter.ID = transaction.ID
-	formatter.Name = transaction.User.Name
+	formatter.Name = backerName(transaction)
 	formatter.Amount = transaction.Amount
 	formatter.Status = transaction.Status
 	formatter.CreatedAt = transaction.CreatedAt.Format(helper.DateTimeFormat)
@@ -100,14 +100,18 @@ func FormatUserTransactions(transactions []Transaction) []UserTransactionFormatt
 }
 
 type TransactionFormatter struct {
-	ID         int    `json:"id"`
-	CampaignID int    `json:"campaign_id"`
-	UserID     int    `json:"user_id"`
-	Amount     int    `json:"amount"`
-	Status     string `json:"status"`
-	Code       string `json:"code"`
-	PaymentURL string `json:"payment_url"`
-	CreatedAt  string `json:"created_at"`
+	ID            int    `json:"id"`
+	CampaignID    int    `json:"campaign_id"`
+	UserID        int    `json:"user_id"`
+	Amount        int    `json:"amount"`
+	Status        string `json:"status"`
+	Code          string `json:"code"`
+	PaymentURL    string `json:"payment_url"`
+	Perk          string `json:"perk"`
+	IsAnonymous   bool   `json:"is_anonymous"`
+	Message       string `json:"message"`
+	MessageStatus string `json:"message_status"`
+	CreatedAt     string `json:"created_at"`
 }
 
 func FormatTransaction(transaction Transaction) TransactionFormatter {
@@ -119,10 +123,198 @@ func FormatTransaction(transaction Transaction) TransactionFormatter {
 	formatter.Status = transaction.Status
 	formatter.Code = transaction.Code
 	formatter.PaymentURL = transaction.PaymentURL
+	formatter.Perk = transaction.Perk
+	formatter.IsAnonymous = transaction.IsAnonymous
+	formatter.Message = transaction.Message
+	formatter.MessageStatus = transaction.MessageStatus
 	formatter.CreatedAt = transaction.CreatedAt.Format(helper.DateTimeFormat)
 	return formatter
 }
 
+type SupporterMessageFormatter struct {
+	Name      string `json:"name"`
+	ImageURL  string `json:"image_url"`
+	Message   string `json:"message"`
+	CreatedAt string `json:"created_at"`
+}
+
+func FormatSupporterMessage(transaction Transaction) SupporterMessageFormatter {
+	formatter := SupporterMessageFormatter{}
+	formatter.Name = backerName(transaction)
+	formatter.ImageURL = ""
+	formatter.Message = transaction.Message
+	formatter.CreatedAt = transaction.CreatedAt.Format(helper.DateTimeFormat)
+
+	if !transaction.IsAnonymous {
+		formatter.ImageURL = buildImageURL(transaction.User.AvatarFileName)
+	}
+
+	return formatter
+}
+
+func FormatSupporterMessages(transactions []Transaction) []SupporterMessageFormatter {
+	messagesFormatter := []SupporterMessageFormatter{}
+
+	for _, transaction := range transactions {
+		messagesFormatter = append(messagesFormatter, FormatSupporterMessage(transaction))
+	}
+
+	return messagesFormatter
+}
+
+type BackerFormatter struct {
+	Name      string `json:"name"`
+	ImageURL  string `json:"image_url"`
+	Amount    int    `json:"amount"`
+	CreatedAt string `json:"created_at"`
+}
+
+func FormatBacker(transaction Transaction) BackerFormatter {
+	formatter := BackerFormatter{}
+	formatter.Name = backerName(transaction)
+	formatter.ImageURL = ""
+	formatter.Amount = transaction.Amount - transaction.RefundedAmount
+	formatter.CreatedAt = transaction.CreatedAt.Format(helper.DateTimeFormat)
+
+	if !transaction.IsAnonymous {
+		formatter.ImageURL = buildImageURL(transaction.User.AvatarFileName)
+	}
+
+	return formatter
+}
+
+type BackersPageFormatter struct {
+	Backers []BackerFormatter `json:"backers"`
+	Page    int               `json:"page"`
+	Limit   int               `json:"limit"`
+	Total   int64             `json:"total"`
+}
+
+func FormatBackersPage(transactions []Transaction, page int, limit int, total int64) BackersPageFormatter {
+	formatter := BackersPageFormatter{}
+	formatter.Backers = []BackerFormatter{}
+	formatter.Page = page
+	formatter.Limit = limit
+	formatter.Total = total
+
+	for _, transaction := range transactions {
+		formatter.Backers = append(formatter.Backers, FormatBacker(transaction))
+	}
+
+	return formatter
+}
+
+type LeaderboardEntryFormatter struct {
+	Rank         int    `json:"rank"`
+	Name         string `json:"name"`
+	ImageURL     string `json:"image_url"`
+	TotalAmount  int    `json:"total_amount"`
+	BackingCount int    `json:"backing_count"`
+}
+
+func FormatLeaderboard(totals []BackerTotal) []LeaderboardEntryFormatter {
+	leaderboardFormatter := []LeaderboardEntryFormatter{}
+
+	for i, total := range totals {
+		formatter := LeaderboardEntryFormatter{}
+		formatter.Rank = i + 1
+		formatter.Name = AnonymousBackerName
+		formatter.ImageURL = ""
+		formatter.TotalAmount = total.TotalAmount
+		formatter.BackingCount = total.BackingCount
+
+		if !total.IsAnonymous {
+			formatter.Name = total.Name
+			formatter.ImageURL = buildImageURL(total.AvatarFileName)
+		}
+
+		leaderboardFormatter = append(leaderboardFormatter, formatter)
+	}
+
+	return leaderboardFormatter
+}
+
+type FraudAlertFormatter struct {
+	ID                int    `json:"id"`
+	TransactionID     int    `json:"transaction_id"`
+	TransactionCode   string `json:"transaction_code"`
+	TransactionStatus string `json:"transaction_status"`
+	CampaignID        int    `json:"campaign_id"`
+	Amount            int    `json:"amount"`
+	Kind              string `json:"kind"`
+	Expected          string `json:"expected"`
+	Received          string `json:"received"`
+	Status            string `json:"status"`
+	Resolution        string `json:"resolution"`
+	ResolutionNote    string `json:"resolution_note"`
+	CreatedAt         string `json:"created_at"`
+}
+
+func FormatFraudAlert(alert FraudAlert) FraudAlertFormatter {
+	formatter := FraudAlertFormatter{}
+	formatter.ID = alert.ID
+	formatter.TransactionID = alert.TransactionID
+	formatter.TransactionCode = alert.Transaction.Code
+	formatter.TransactionStatus = alert.Transaction.Status
+	formatter.CampaignID = alert.Transaction.CampaignID
+	formatter.Amount = alert.Transaction.Amount
+	formatter.Kind = alert.Kind
+	formatter.Expected = alert.Expected
+	formatter.Received = alert.Received
+	formatter.Status = alert.Status
+	formatter.Resolution = alert.Resolution
+	formatter.ResolutionNote = alert.ResolutionNote
+	formatter.CreatedAt = alert.CreatedAt.Format(helper.DateTimeFormat)
+
+	return formatter
+}
+
+func FormatFraudAlerts(alerts []FraudAlert) []FraudAlertFormatter {
+	alertsFormatter := []FraudAlertFormatter{}
+
+	for _, alert := range alerts {
+		alertsFormatter = append(alertsFormatter, FormatFraudAlert(alert))
+	}
+
+	return alertsFormatter
+}
+
+type RefundFormatter struct {
+	ID            int    `json:"id"`
+	TransactionID int    `json:"transaction_id"`
+	Amount        int    `json:"amount"`
+	Reason        string `json:"reason"`
+	Note          string `json:"note"`
+	Status        string `json:"status"`
+	CreatedAt     string `json:"created_at"`
+}
+
+func FormatRefund(refund Refund) RefundFormatter {
+	formatter := RefundFormatter{}
+	formatter.ID = refund.ID
+	formatter.TransactionID = refund.TransactionID
+	formatter.Amount = refund.Amount
+	formatter.Reason = refund.Reason
+	formatter.Note = refund.Note
+	formatter.Status = refund.Status
+	formatter.CreatedAt = refund.CreatedAt.Format(helper.DateTimeFormat)
+	return formatter
+}
+
+// backerName is the name shown for a transaction's backer, in public views and to
+// the campaign owner alike.
+func backerName(transaction Transaction) string {
+	if transaction.IsAnonymous {
+		return AnonymousBackerName
+	}
+
+	if transaction.UserID == 0 {
+		return transaction.GuestName
+	}
+
+	return transaction.User.Name
+}
+
 func buildImageURL(fileName string) string {
 	if fileName == "" {
 		return ""
@@ -0,0 +1,251 @@
+package transaction
+
+import (
+	"backer/config"
+	"backer/logger"
+	"backer/payment"
+	"context"
+	"strconv"
+	"strings"
+)
+
+// Fraud alert kinds
+const (
+	FraudAmountMismatch      = "amount_mismatch"
+	FraudCurrencyMismatch    = "currency_mismatch"
+	FraudPaymentTypeMismatch = "payment_type_not_allowed"
+	FraudProviderWarning     = "provider_warning"
+)
+
+// transactionCurrency is the only currency transactions are created in.
+const transactionCurrency = "IDR"
+
+// checkNotificationIntegrity cross-checks a paid notification against the stored
+// transaction. The signature only proves the notification came from the provider,
+// not that it is about the payment we asked for.
+func checkNotificationIntegrity(transaction Transaction, event payment.Event) []FraudAlert {
+	var alerts []FraudAlert
+
+	notifiedAmount, err := parseGrossAmount(event.GrossAmount)
+	if err != nil || notifiedAmount != transaction.Amount {
+		alerts = append(alerts, FraudAlert{
+			Kind:     FraudAmountMismatch,
+			Expected: strconv.Itoa(transaction.Amount),
+			Received: event.GrossAmount,
+		})
+	}
+
+	// Older notifications may leave the currency out
+	if event.Currency != "" && !strings.EqualFold(event.Currency, transactionCurrency) {
+		alerts = append(alerts, FraudAlert{
+			Kind:     FraudCurrencyMismatch,
+			Expected: transactionCurrency,
+			Received: event.Currency,
+		})
+	}
+
+	if !paymentTypeAllowed(event.PaymentType) {
+		alerts = append(alerts, FraudAlert{
+			Kind:     FraudPaymentTypeMismatch,
+			Expected: strings.Join(config.AppConfig.AllowedPaymentTypes, ","),
+			Received: event.PaymentType,
+		})
+	}
+
+	for _, warning := range event.Warnings {
+		alerts = append(alerts, FraudAlert{
+			Kind:     FraudProviderWarning,
+			Received: warning,
+		})
+	}
+
+	return alerts
+}
+
+func paymentTypeAllowed(paymentType string) bool {
+	if len(config.AppConfig.AllowedPaymentTypes) == 0 {
+		return true
+	}
+
+	for _, allowed := range config.AppConfig.AllowedPaymentTypes {
+		if strings.EqualFold(allowed, paymentType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// holdForReview parks an open transaction in "review" without crediting its
+// campaign and records why. A transaction that is already under review, settled or
+// cancelled is left alone. The notification itself was handled, so no error is
+// returned and the provider does not retry it.
+func (s *service) holdForReview(ctx context.Context, transaction Transaction, alerts []FraudAlert) (Transaction, error) {
+	for i := range alerts {
+		alerts[i].TransactionID = transaction.ID
+		alerts[i].Status = "open"
+	}
+
+	held, err := s.repository.HoldForReview(ctx, transaction, openStatuses, alerts)
+	if err != nil || !held {
+		return transaction, err
+	}
+
+	transaction.Status = "review"
+
+	for _, alert := range alerts {
+		logger.FromContext(ctx).Warn("Fraud alert", "transaction_id", transaction.ID, "code", transaction.Code, "kind", alert.Kind, "expected", alert.Expected, "received", alert.Received)
+	}
+
+	return transaction, nil
+}
+
+func (s *service) GetFraudAlerts(ctx context.Context, input GetFraudAlertsInput) ([]FraudAlert, error) {
+	ctx, span := tracer.Start(ctx, "transaction.GetFraudAlerts")
+	defer span.End()
+
+	alerts, err := s.repository.GetFraudAlerts(ctx, input.Status)
+	if err != nil {
+		return alerts, err
+	}
+
+	return alerts, nil
+}
+
+// ResolveFraudAlert settles a transaction held for review and closes all of its
+// open alerts. Approving credits the campaign with the stored amount; rejecting
+// cancels the transaction and refunds the payment the provider already captured.
+func (s *service) ResolveFraudAlert(ctx context.Context, inputID GetFraudAlertInput, inputData ResolveFraudAlertInput) (FraudAlert, error) {
+	ctx, span := tracer.Start(ctx, "transaction.ResolveFraudAlert")
+	defer span.End()
+
+	alert, err := s.repository.GetFraudAlertByID(ctx, inputID.ID)
+	if err != nil {
+		return alert, err
+	}
+
+	if alert.ID == 0 {
+		return alert, ErrFraudAlertNotFound
+	}
+
+	if alert.Status != "open" {
+		return alert, ErrFraudAlertResolved
+	}
+
+	transaction := alert.Transaction
+	if transaction.Status == "review" {
+		transaction, err = s.resolveReview(ctx, transaction, inputData.Action)
+		if err != nil {
+			return alert, err
+		}
+	}
+
+	alerts, err := s.repository.GetOpenFraudAlertsByTransactionID(ctx, transaction.ID)
+	if err != nil {
+		return alert, err
+	}
+
+	// The alerts stay open until the refund is on its way, so a failed attempt can be
+	// resolved again
+	if inputData.Action == "reject" && transaction.Status == "cancelled" {
+		if err := s.refundRejectedPayment(ctx, transaction, alerts, inputData); err != nil {
+			return alert, err
+		}
+	}
+
+	resolution := "approved"
+	if inputData.Action == "reject" {
+		resolution = "rejected"
+	}
+
+	for _, openAlert := range alerts {
+		openAlert.Status = "resolved"
+		openAlert.Resolution = resolution
+		openAlert.ResolvedBy = inputData.User.ID
+		openAlert.ResolutionNote = inputData.Note
+
+		updatedAlert, err := s.repository.UpdateFraudAlert(ctx, openAlert)
+		if err != nil {
+			return alert, err
+		}
+
+		if updatedAlert.ID == alert.ID {
+			alert = updatedAlert
+		}
+	}
+
+	alert.Transaction = transaction
+
+	return alert, nil
+}
+
+// refundRejectedPayment gives a payment rejected after review back to the backer.
+// Held transactions were paid at the provider, only never credited to the campaign.
+// A refund already pending or done from an earlier attempt is not requested again.
+func (s *service) refundRejectedPayment(ctx context.Context, transaction Transaction, alerts []FraudAlert, inputData ResolveFraudAlertInput) error {
+	refunds, err := s.repository.GetRefundsByTransactionID(ctx, transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, refund := range refunds {
+		if refund.Status != "failed" {
+			return nil
+		}
+	}
+
+	refund := Refund{}
+	refund.TransactionID = transaction.ID
+	refund.RequestedBy = inputData.User.ID
+	refund.Amount = capturedAmount(transaction, alerts)
+	refund.Reason = "Payment rejected after fraud review"
+	refund.Note = inputData.Note
+	refund.Status = "pending"
+
+	newRefund, err := s.repository.SaveRefundRequest(ctx, refund, []string{"cancelled"})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.requestRefund(ctx, transaction, newRefund)
+	return err
+}
+
+// capturedAmount is how much the provider took for a transaction held for review:
+// the notified amount when it came in below the stored one, otherwise the stored one.
+func capturedAmount(transaction Transaction, alerts []FraudAlert) int {
+	for _, alert := range alerts {
+		if alert.Kind != FraudAmountMismatch {
+			continue
+		}
+
+		amount, err := parseGrossAmount(alert.Received)
+		if err == nil && amount > 0 && amount < transaction.Amount {
+			return amount
+		}
+	}
+
+	return transaction.Amount
+}
+
+// resolveReview settles or cancels a transaction held for review. It fails with
+// ErrFraudAlertResolved when another admin resolved it first.
+func (s *service) resolveReview(ctx context.Context, transaction Transaction, action string) (Transaction, error) {
+	var updatedTransaction Transaction
+	var err error
+
+	if action == "approve" {
+		updatedTransaction, err = s.settlePaid(ctx, transaction, []string{"review"})
+	} else {
+		updatedTransaction, err = s.cancel(ctx, transaction, []string{"review"})
+	}
+	if err != nil {
+		return updatedTransaction, err
+	}
+
+	if updatedTransaction.Status == "review" {
+		return updatedTransaction, ErrFraudAlertResolved
+	}
+
+	return updatedTransaction, nil
+}
@@ -0,0 +1,95 @@
+package transaction
+
+import (
+	"backer/config"
+	"backer/payment"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CreateGuestTransaction funds a campaign for a backer without an account.
+func (s *service) CreateGuestTransaction(ctx context.Context, input CreateGuestTransactionInput) (Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.CreateGuestTransaction")
+	defer span.End()
+
+	transaction := Transaction{}
+	transaction.CampaignID = input.CampaignID
+	transaction.Amount = input.Amount
+	transaction.GuestName = strings.TrimSpace(input.Name)
+	transaction.GuestEmail = strings.ToLower(strings.TrimSpace(input.Email))
+	transaction.Perk = strings.TrimSpace(input.Perk)
+	transaction.IsAnonymous = input.IsAnonymous
+	transaction.Message = strings.TrimSpace(input.Message)
+	transaction.MessageStatus = moderateMessage(transaction.Message)
+
+	customer := payment.Customer{
+		Name:  transaction.GuestName,
+		Email: transaction.GuestEmail,
+	}
+
+	return s.startCheckout(ctx, transaction, customer)
+}
+
+// ClaimGuestTransactions moves the guest donations made with the email address of
+// a claim link into the user's account, returning how many were claimed. Holding
+// the link proves access to that address, so every unclaimed donation made with it
+// is claimed at once.
+func (s *service) ClaimGuestTransactions(ctx context.Context, input ClaimGuestTransactionsInput) (int64, error) {
+	ctx, span := tracer.Start(ctx, "transaction.ClaimGuestTransactions")
+	defer span.End()
+
+	transaction, err := s.repository.GetByClaimTokenHash(ctx, hashClaimToken(input.Token))
+	if err != nil {
+		return 0, err
+	}
+
+	if transaction.ID == 0 || transaction.ClaimTokenExpiresAt == nil || time.Now().After(*transaction.ClaimTokenExpiresAt) {
+		return 0, ErrInvalidClaimToken
+	}
+
+	return s.repository.ClaimGuestTransactions(ctx, transaction.GuestEmail, input.User.ID)
+}
+
+// issueClaimLink stores a new claim token on a guest transaction and returns the
+// link to claim it, along with when the link expires.
+func (s *service) issueClaimLink(ctx context.Context, transaction Transaction) (string, time.Time, error) {
+	token, err := generateClaimToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(config.AppConfig.GuestClaimTTL)
+	transaction.ClaimTokenHash = hashClaimToken(token)
+	transaction.ClaimTokenExpiresAt = &expiresAt
+
+	err = s.repository.UpdateClaimToken(ctx, transaction)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	claimURL := config.AppConfig.FrontendURL + "/claim-donation?token=" + url.QueryEscape(token)
+
+	return claimURL, expiresAt, nil
+}
+
+// generateClaimToken returns the secret put in a claim link. Only its hash is stored.
+func generateClaimToken() (string, error) {
+	randomBytes := make([]byte, 32)
+
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(randomBytes), nil
+}
+
+func hashClaimToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
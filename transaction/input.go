@@ -1,24 +1,113 @@
 package transaction
 
-import "backer/user"
+import (
+	"backer/user"
+	"time"
+)
 
 type GetCampaignTransactionsInput struct {
 	ID   int `uri:"id" binding:"required"`
 	User user.User
 }
 
-type CreateTransactionInput struct {
-	CampaignID int `json:"campaign_id" binding:"required"`
-	Amount     int `json:"amount" binding:"required"`
+type GetTransactionInput struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+// CreateRefundInput leaves Amount empty for a full refund of what is left on the transaction.
+type CreateRefundInput struct {
+	Amount int    `json:"amount" binding:"omitempty,gt=0"`
+	Reason string `json:"reason" binding:"required,oneof=duplicate fraudulent requested_by_customer campaign_cancelled"`
+	Note   string `json:"note"`
+	User   user.User
+}
+
+// ChargeRecurringInput describes one billing cycle of a recurring pledge.
+type ChargeRecurringInput struct {
+	PledgeID   int
+	CampaignID int
+	Amount     int
+	Provider   string
+	CardToken  string
 	User       user.User
 }
 
-type TransactionNotificationInput struct {
-	TransactionStatus string `json:"transaction_status"`
-	OrderID           string `json:"order_id"`
-	PaymentType       string `json:"payment_type"`
-	FraudStatus       string `json:"fraud_status"`
-	StatusCode        string `json:"status_code"`
-	GrossAmount       string `json:"gross_amount"`
-	SignatureKey      string `json:"signature_key"`
+type CreateTransactionInput struct {
+	CampaignID  int    `json:"campaign_id" binding:"required"`
+	Amount      int    `json:"amount" binding:"required,gt=0"`
+	Perk        string `json:"perk"`
+	IsAnonymous bool   `json:"is_anonymous"`
+	Message     string `json:"message" binding:"max=280"`
+	User        user.User
+}
+
+// CreateGuestTransactionInput funds a campaign without an account. The receipt and
+// the link to claim the donation later are sent to Email.
+type CreateGuestTransactionInput struct {
+	CampaignID  int    `json:"campaign_id" binding:"required"`
+	Amount      int    `json:"amount" binding:"required,gt=0"`
+	Name        string `json:"name" binding:"required,max=255"`
+	Email       string `json:"email" binding:"required,email,max=255"`
+	Perk        string `json:"perk"`
+	IsAnonymous bool   `json:"is_anonymous"`
+	Message     string `json:"message" binding:"max=280"`
+}
+
+type ClaimGuestTransactionsInput struct {
+	Token string `json:"token" binding:"required"`
+	User  user.User
+}
+
+type GetReceiptInput struct {
+	ID   int `uri:"id" binding:"required"`
+	User user.User
+}
+
+type GetFraudAlertsInput struct {
+	Status string `form:"status" binding:"omitempty,oneof=open resolved"`
+}
+
+type GetFraudAlertInput struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+// ResolveFraudAlertInput approves a transaction held for review, crediting its
+// campaign with the stored amount, or rejects it.
+type ResolveFraudAlertInput struct {
+	Action string `json:"action" binding:"required,oneof=approve reject"`
+	Note   string `json:"note"`
+	User   user.User
+}
+
+type GetSupportersWallInput struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+// GetCampaignBackersInput pages through a campaign's backers; Page and Limit
+// come from the query string and fall back to defaults when empty.
+type GetCampaignBackersInput struct {
+	ID    int `uri:"id" binding:"required"`
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// ExportCampaignTransactionsInput filters a campaign's transaction export. From and
+// To are inclusive days.
+type ExportCampaignTransactionsInput struct {
+	ID     int       `uri:"id" binding:"required"`
+	Format string    `form:"format" binding:"omitempty,oneof=csv xlsx"`
+	Status string    `form:"status" binding:"omitempty,oneof=pending paid review cancelled expired refunded"`
+	From   time.Time `form:"from" time_format:"2006-01-02"`
+	To     time.Time `form:"to" time_format:"2006-01-02"`
+	User   user.User
+}
+
+type GetLeaderboardInput struct {
+	ID    int `uri:"id" binding:"required"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type ModerateMessageInput struct {
+	Status string `json:"status" binding:"required,oneof=published hidden"`
+	User   user.User
 }
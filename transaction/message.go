@@ -0,0 +1,86 @@
+package transaction
+
+import (
+	"backer/config"
+	"context"
+	"strings"
+)
+
+// AnonymousBackerName replaces the name of backers who chose to give anonymously.
+const AnonymousBackerName = "Anonymous"
+
+// supportersWallLimit is how many messages the supporters wall shows.
+const supportersWallLimit = 50
+
+// GetSupportersWall returns the latest published messages of a campaign's backers.
+func (s *service) GetSupportersWall(ctx context.Context, input GetSupportersWallInput) ([]Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.GetSupportersWall")
+	defer span.End()
+
+	campaign, err := s.campaignRepository.FindByID(ctx, input.ID)
+	if err != nil {
+		return []Transaction{}, err
+	}
+
+	if campaign.ID == 0 {
+		return []Transaction{}, ErrCampaignNotFound
+	}
+
+	transactions, err := s.repository.GetMessagesByCampaignID(ctx, input.ID, supportersWallLimit)
+	if err != nil {
+		return transactions, err
+	}
+
+	return transactions, nil
+}
+
+// ModerateMessage lets the campaign owner or an admin publish or hide a backer's message.
+func (s *service) ModerateMessage(ctx context.Context, inputID GetTransactionInput, inputData ModerateMessageInput) (Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.ModerateMessage")
+	defer span.End()
+
+	transaction, err := s.repository.GetByID(ctx, inputID.ID)
+	if err != nil {
+		return transaction, err
+	}
+
+	if transaction.ID == 0 || transaction.Message == "" {
+		return transaction, ErrTransactionNotFound
+	}
+
+	campaign, err := s.campaignRepository.FindByID(ctx, transaction.CampaignID)
+	if err != nil {
+		return transaction, err
+	}
+
+	if inputData.User.Role != "admin" && campaign.UserID != inputData.User.ID {
+		return transaction, ErrNotAuthorized
+	}
+
+	transaction.MessageStatus = inputData.Status
+
+	err = s.repository.UpdateMessageStatus(ctx, transaction)
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+// moderateMessage decides whether a new message is published right away. Messages
+// containing a word from MESSAGE_BLOCKLIST are flagged until the campaign owner
+// reviews them.
+func moderateMessage(message string) string {
+	if message == "" {
+		return ""
+	}
+
+	lowerMessage := strings.ToLower(message)
+	for _, word := range config.AppConfig.MessageBlocklist {
+		if strings.Contains(lowerMessage, word) {
+			return "flagged"
+		}
+	}
+
+	return "published"
+}
@@ -0,0 +1,191 @@
+package transaction
+
+import (
+	"backer/config"
+	"backer/logger"
+	"backer/mailer"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GetReceipt returns the receipt of a paid transaction and its PDF, for the backer
+// who paid it or an admin. Receipts missing on disk are generated again under the
+// same number.
+func (s *service) GetReceipt(ctx context.Context, input GetReceiptInput) (Receipt, []byte, error) {
+	ctx, span := tracer.Start(ctx, "transaction.GetReceipt")
+	defer span.End()
+
+	transaction, err := s.repository.GetDetailsByID(ctx, input.ID)
+	if err != nil {
+		return Receipt{}, nil, err
+	}
+
+	if transaction.ID == 0 {
+		return Receipt{}, nil, ErrTransactionNotFound
+	}
+
+	if input.User.Role != "admin" && (transaction.UserID == 0 || transaction.UserID != input.User.ID) {
+		return Receipt{}, nil, ErrNotAuthorized
+	}
+
+	if transaction.Status != "paid" {
+		return Receipt{}, nil, ErrReceiptNotAvailable
+	}
+
+	return s.issueReceipt(ctx, transaction)
+}
+
+// issueReceipt numbers the receipt of a transaction on first use and makes sure its
+// PDF exists on disk. The transaction must be loaded with its user and campaign.
+func (s *service) issueReceipt(ctx context.Context, transaction Transaction) (Receipt, []byte, error) {
+	receipt, err := s.repository.GetReceiptByTransactionID(ctx, transaction.ID)
+	if err != nil {
+		return receipt, nil, err
+	}
+
+	if receipt.ID != 0 {
+		pdf, err := os.ReadFile(filepath.Join(config.AppConfig.ReceiptDir, receipt.FileName))
+		if err == nil {
+			return receipt, pdf, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			return receipt, nil, err
+		}
+	} else {
+		// The ID keeps receipt numbers unique and sequential without a separate counter
+		receipt, err = s.repository.SaveReceipt(ctx, Receipt{TransactionID: transaction.ID}, func(receipt Receipt) string {
+			return fmt.Sprintf("%s-%d-%06d", config.AppConfig.ReceiptPrefix, receipt.CreatedAt.Year(), receipt.ID)
+		})
+		if err != nil {
+			return receipt, nil, err
+		}
+	}
+
+	pdf, err := renderReceiptPDF(receipt, transaction)
+	if err != nil {
+		return receipt, nil, err
+	}
+
+	err = os.MkdirAll(config.AppConfig.ReceiptDir, 0o755)
+	if err != nil {
+		return receipt, nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(config.AppConfig.ReceiptDir, receipt.FileName), pdf, 0o644)
+	if err != nil {
+		return receipt, nil, err
+	}
+
+	return receipt, pdf, nil
+}
+
+// WaitForReceipts waits until the receipts being sent in the background are out, or
+// until ctx is done, and reports whether they all went out.
+func (s *service) WaitForReceipts(ctx context.Context) bool {
+	done := make(chan struct{})
+	go func() {
+		s.receipts.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sendReceipt issues the receipt of a newly paid transaction and emails it to the
+// backer. Guests also get a link to claim the donation into an account. The payment
+// is already recorded, so failures are only logged.
+func (s *service) sendReceipt(ctx context.Context, transaction Transaction) {
+	log := logger.FromContext(ctx)
+	claimURL := ""
+
+	if transaction.UserID == 0 {
+		link, expiresAt, err := s.issueClaimLink(ctx, transaction)
+		if err != nil {
+			log.Error("Failed to issue claim link", "transaction_id", transaction.ID, "error", err)
+		} else {
+			claimURL = fmt.Sprintf("%s\n\nThe link is valid until %s.", link, expiresAt.Format("02 Jan 2006"))
+		}
+	}
+
+	details, err := s.repository.GetDetailsByID(ctx, transaction.ID)
+	if err != nil {
+		log.Error("Failed to load transaction for its receipt", "transaction_id", transaction.ID, "error", err)
+		return
+	}
+
+	receipt, pdf, err := s.issueReceipt(ctx, details)
+	if err != nil {
+		log.Error("Failed to issue receipt", "transaction_id", transaction.ID, "error", err)
+		return
+	}
+
+	name, email := donorOf(details)
+	if email == "" {
+		return
+	}
+
+	var body strings.Builder
+
+	fmt.Fprintf(&body, "Hi %s,\n\nThank you for backing \"%s\"!\n\n", name, details.Campaign.Name)
+	fmt.Fprintf(&body, "Receipt number: %s\nAmount: %s\nTransaction code: %s\n\n", receipt.Number, formatRupiah(details.Amount), details.Code)
+	body.WriteString("Your receipt is attached to this email.\n")
+
+	if claimURL != "" {
+		body.WriteString("\nCreate an account or log in to keep track of your donations. This link adds this\n")
+		body.WriteString("donation, and any other you made as a guest with this email address, to your account:\n\n")
+		body.WriteString(claimURL + "\n")
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Your donation receipt for " + details.Campaign.Name,
+		Body:    body.String(),
+		Attachments: []mailer.Attachment{{
+			FileName:    receipt.FileName,
+			ContentType: "application/pdf",
+			Data:        pdf,
+		}},
+	})
+	if err != nil {
+		log.Error("Failed to send receipt", "transaction_id", transaction.ID, "error", err)
+	}
+}
+
+// donorOf returns who paid a transaction, whether a registered user or a guest.
+func donorOf(transaction Transaction) (string, string) {
+	if transaction.UserID == 0 {
+		return transaction.GuestName, transaction.GuestEmail
+	}
+
+	return transaction.User.Name, transaction.User.Email
+}
+
+// formatRupiah formats an amount the Indonesian way, e.g. IDR 1.500.000.
+func formatRupiah(amount int) string {
+	digits := fmt.Sprintf("%d", amount)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return "IDR " + sign + grouped.String()
+}
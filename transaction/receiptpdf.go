@@ -0,0 +1,74 @@
+package transaction
+
+import (
+	"backer/config"
+	"bytes"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// renderReceiptPDF lays out a one-page A4 donation receipt.
+func renderReceiptPDF(receipt Receipt, transaction Transaction) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Donation receipt "+receipt.Number, true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	// The core fonts only cover cp1252, names and addresses may not
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, tr(config.AppConfig.OrgName), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	if config.AppConfig.OrgAddress != "" {
+		pdf.MultiCell(0, 5, tr(config.AppConfig.OrgAddress), "", "L", false)
+	}
+	if config.AppConfig.OrgTaxID != "" {
+		pdf.CellFormat(0, 5, tr("Tax ID: "+config.AppConfig.OrgTaxID), "", 1, "L", false, 0, "")
+	}
+	if config.AppConfig.OrgEmail != "" {
+		pdf.CellFormat(0, 5, tr(config.AppConfig.OrgEmail), "", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(10)
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, "Donation Receipt", "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	donorName, donorEmail := donorOf(transaction)
+
+	// Every settled transaction has PaidAt; the last update is the closest fallback
+	paidAt := transaction.UpdatedAt
+	if transaction.PaidAt != nil {
+		paidAt = *transaction.PaidAt
+	}
+
+	rows := [][2]string{
+		{"Receipt number", receipt.Number},
+		{"Date", paidAt.Format("02 January 2006")},
+		{"Donor", donorName},
+		{"Email", donorEmail},
+		{"Campaign", transaction.Campaign.Name},
+		{"Transaction code", transaction.Code},
+		{"Amount", formatRupiah(transaction.Amount)},
+	}
+
+	for _, row := range rows {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(45, 8, row[0], "B", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(0, 8, tr(row[1]), "B", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(10)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.MultiCell(0, 5, tr("Thank you for your support. This receipt confirms a donation received by "+config.AppConfig.OrgName+" on behalf of the campaign above."), "", "L", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,185 @@
+package transaction
+
+import (
+	"backer/payment"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Discrepancy kinds reported by Reconcile
+const (
+	DiscrepancyPaidAtProvider      = "paid_at_provider"
+	DiscrepancyCancelledAtProvider = "cancelled_at_provider"
+	DiscrepancyHeldForReview       = "held_for_review"
+	DiscrepancyRefundedAtProvider  = "refunded_at_provider"
+	DiscrepancyStatusCheckFailed   = "status_check_failed"
+)
+
+type Discrepancy struct {
+	TransactionID  int
+	OrderID        string
+	Kind           string
+	LocalStatus    string
+	ProviderStatus string
+	LocalAmount    int
+	ProviderAmount string
+	Detail         string
+}
+
+type ReconcileReport struct {
+	Checked           int
+	Updated           int
+	MissingAtProvider int
+	Discrepancies     []Discrepancy
+}
+
+// Reconcile asks the payment provider about every transaction that has been pending
+// for longer than staleAfter, or that we expired locally, and applies the provider's
+// status, in case the notification for it never reached us. A payment that landed
+// after the local expiry is credited like any other. Expired transactions the
+// provider never heard of are cancelled, as they can no longer be paid. The status goes through the same checks as
+// a notification, so a mismatching payment is held for review here too. Refunds left
+// pending for as long are completed once the provider lists them as done.
+func (s *service) Reconcile(ctx context.Context, staleAfter time.Duration) (ReconcileReport, error) {
+	ctx, span := tracer.Start(ctx, "transaction.Reconcile")
+	defer span.End()
+
+	report := ReconcileReport{}
+	cutoff := time.Now().Add(-staleAfter)
+
+	transactions, err := s.repository.GetOpenBefore(ctx, openStatuses, cutoff)
+	if err != nil {
+		return report, err
+	}
+
+	for _, transaction := range transactions {
+		// Transactions left over are checked by the next run
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		report.Checked++
+
+		orderID := orderIDOf(transaction)
+
+		event, err := s.paymentService.GetStatus(ctx, transaction.Provider, orderID)
+		if errors.Is(err, payment.ErrTransactionNotFound) {
+			report.MissingAtProvider++
+
+			if transaction.Status == "expired" {
+				if _, err := s.cancel(ctx, transaction, []string{"expired"}); err != nil {
+					return report, err
+				}
+			}
+			continue
+		}
+
+		if err != nil {
+			report.Discrepancies = append(report.Discrepancies, Discrepancy{
+				TransactionID: transaction.ID,
+				OrderID:       orderID,
+				Kind:          DiscrepancyStatusCheckFailed,
+				LocalStatus:   transaction.Status,
+				LocalAmount:   transaction.Amount,
+				Detail:        err.Error(),
+			})
+			continue
+		}
+
+		discrepancy := Discrepancy{
+			TransactionID:  transaction.ID,
+			OrderID:        orderID,
+			LocalStatus:    transaction.Status,
+			ProviderStatus: event.RawStatus,
+			LocalAmount:    transaction.Amount,
+			ProviderAmount: event.GrossAmount,
+		}
+
+		updatedTransaction, err := s.applyEvent(ctx, transaction, event)
+		if err != nil {
+			return report, err
+		}
+
+		if updatedTransaction.Status == transaction.Status {
+			continue
+		}
+
+		report.Updated++
+
+		switch updatedTransaction.Status {
+		case "paid":
+			discrepancy.Kind = DiscrepancyPaidAtProvider
+		case "cancelled":
+			discrepancy.Kind = DiscrepancyCancelledAtProvider
+		case "review":
+			discrepancy.Kind = DiscrepancyHeldForReview
+		}
+
+		report.Discrepancies = append(report.Discrepancies, discrepancy)
+	}
+
+	transactions, err = s.repository.GetWithPendingRefundsBefore(ctx, cutoff)
+	if err != nil {
+		return report, err
+	}
+
+	for _, transaction := range transactions {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		report.Checked++
+
+		orderID := orderIDOf(transaction)
+
+		event, err := s.paymentService.GetStatus(ctx, transaction.Provider, orderID)
+		if err != nil {
+			report.Discrepancies = append(report.Discrepancies, Discrepancy{
+				TransactionID: transaction.ID,
+				OrderID:       orderID,
+				Kind:          DiscrepancyStatusCheckFailed,
+				LocalStatus:   transaction.Status,
+				LocalAmount:   transaction.Amount,
+				Detail:        err.Error(),
+			})
+			continue
+		}
+
+		completed, err := s.applyRefundNotification(ctx, transaction, event.Refunds)
+		if err != nil {
+			return report, err
+		}
+
+		if completed == 0 {
+			continue
+		}
+
+		report.Updated++
+		report.Discrepancies = append(report.Discrepancies, Discrepancy{
+			TransactionID:  transaction.ID,
+			OrderID:        orderID,
+			Kind:           DiscrepancyRefundedAtProvider,
+			LocalStatus:    transaction.Status,
+			ProviderStatus: event.RawStatus,
+			LocalAmount:    transaction.Amount,
+			ProviderAmount: event.GrossAmount,
+			Detail:         fmt.Sprintf("%d refund(s) completed", completed),
+		})
+	}
+
+	return report, nil
+}
+
+// parseGrossAmount converts a provider amount such as "150000.00" into whole rupiah.
+func parseGrossAmount(grossAmount string) (int, error) {
+	whole, fraction, _ := strings.Cut(grossAmount, ".")
+	if strings.Trim(fraction, "0") != "" {
+		return 0, fmt.Errorf("gross amount %q has a fractional part", grossAmount)
+	}
+
+	return strconv.Atoi(whole)
+}
@@ -0,0 +1,298 @@
+package transaction
+
+import (
+	"backer/campaign"
+	"backer/database/databasetest"
+	"backer/ledger"
+	"backer/mailer"
+	"backer/payment"
+	"backer/user"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recordingMailer keeps the emails it is asked to send.
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, message mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func (m *recordingMailer) sent() []mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]mailer.Message(nil), m.messages...)
+}
+
+// newMidtransStub serves the Midtrans status API for the given orders. Unknown
+// orders get Midtrans' 404 answer. Refund requests are answered with the status code
+// set for the order in refunds.
+func newMidtransStub(t *testing.T, statuses map[string]map[string]any, refunds map[string]int) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if orderID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v2/"), "/refund"); ok && r.Method == http.MethodPost {
+			statusCode, ok := refunds[orderID]
+			if !ok {
+				statusCode = http.StatusNotFound
+			}
+
+			if statusCode >= http.StatusInternalServerError {
+				w.WriteHeader(statusCode)
+			}
+			json.NewEncoder(w).Encode(map[string]string{"status_code": strconv.Itoa(statusCode), "status_message": http.StatusText(statusCode), "order_id": orderID})
+			return
+		}
+
+		orderID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v2/"), "/status")
+		if r.Method != http.MethodGet || !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		status, ok := statuses[orderID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"status_code": "404", "status_message": "Transaction doesn't exist."})
+			return
+		}
+
+		response := map[string]any{"status_code": "200", "order_id": orderID, "currency": "IDR", "payment_type": "bank_transfer"}
+		for key, value := range status {
+			response[key] = value
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("MIDTRANS_API_URL", server.URL)
+	t.Setenv("MIDTRANS_SERVER_KEY", "test-server-key")
+}
+
+func newTestService(t *testing.T) (*service, *gorm.DB, *recordingMailer) {
+	t.Helper()
+
+	t.Setenv("RECEIPT_DIR", t.TempDir())
+	t.Setenv("PLATFORM_FEE_PERCENT", "5")
+	db := databasetest.Open(t)
+
+	registry := payment.NewRegistry("midtrans", payment.NewMidtransProvider())
+	campaignRepository := campaign.NewRepository(db)
+	recorder := &recordingMailer{}
+
+	s := NewService(NewRepository(db), campaignRepository, payment.NewService(registry), recorder)
+
+	return s, db, recorder
+}
+
+func createCampaign(t *testing.T, db *gorm.DB) campaign.Campaign {
+	t.Helper()
+
+	ctx := context.Background()
+
+	owner, err := user.NewRepository(db).Save(ctx, user.User{Name: "Ann", Email: "ann@example.com", Role: "user"})
+	if err != nil {
+		t.Fatalf("saving user: %v", err)
+	}
+
+	newCampaign, err := campaign.NewRepository(db).Save(ctx, campaign.Campaign{UserID: owner.ID, Name: "Clean water", Slug: "clean-water", GoalAmount: 1000000})
+	if err != nil {
+		t.Fatalf("saving campaign: %v", err)
+	}
+
+	return newCampaign
+}
+
+func createPendingTransaction(t *testing.T, db *gorm.DB, campaignID int, amount int) Transaction {
+	t.Helper()
+
+	code, err := generateCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction, err := NewRepository(db).Save(context.Background(), Transaction{
+		CampaignID: campaignID,
+		UserID:     1,
+		Amount:     amount,
+		Status:     "pending",
+		Code:       code,
+		Provider:   "midtrans",
+		CreatedAt:  time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("saving transaction: %v", err)
+	}
+
+	return transaction
+}
+
+func TestReconcile(t *testing.T) {
+	statuses := map[string]map[string]any{}
+	newMidtransStub(t, statuses, nil)
+	s, db, recorder := newTestService(t)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+	paid := createPendingTransaction(t, db, testCampaign.ID, 50000)
+	expired := createPendingTransaction(t, db, testCampaign.ID, 20000)
+	underpaid := createPendingTransaction(t, db, testCampaign.ID, 30000)
+	missing := createPendingTransaction(t, db, testCampaign.ID, 40000)
+
+	statuses[paid.Code] = map[string]any{"transaction_status": "settlement", "gross_amount": "50000.00"}
+	statuses[expired.Code] = map[string]any{"transaction_status": "expire", "gross_amount": "20000.00"}
+	statuses[underpaid.Code] = map[string]any{"transaction_status": "settlement", "gross_amount": "3000.00"}
+
+	report, err := s.Reconcile(ctx, time.Minute)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	if report.Checked != 4 || report.Updated != 3 || report.MissingAtProvider != 1 {
+		t.Errorf("report = %d checked, %d updated, %d missing; want 4, 3, 1", report.Checked, report.Updated, report.MissingAtProvider)
+	}
+
+	kinds := map[int]string{}
+	for _, discrepancy := range report.Discrepancies {
+		kinds[discrepancy.TransactionID] = discrepancy.Kind
+	}
+	wantKinds := map[int]string{
+		paid.ID:      DiscrepancyPaidAtProvider,
+		expired.ID:   DiscrepancyCancelledAtProvider,
+		underpaid.ID: DiscrepancyHeldForReview,
+	}
+	for transactionID, want := range wantKinds {
+		if kinds[transactionID] != want {
+			t.Errorf("discrepancy of transaction %d = %q, want %q", transactionID, kinds[transactionID], want)
+		}
+	}
+
+	wantStatuses := map[int]string{paid.ID: "paid", expired.ID: "cancelled", underpaid.ID: "review", missing.ID: "pending"}
+	for transactionID, want := range wantStatuses {
+		transaction, err := s.repository.GetByID(ctx, transactionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if transaction.Status != want {
+			t.Errorf("status of transaction %d = %q, want %q", transactionID, transaction.Status, want)
+		}
+	}
+
+	alerts, err := s.repository.GetOpenFraudAlertsByTransactionID(ctx, underpaid.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].Kind != FraudAmountMismatch {
+		t.Errorf("fraud alerts of the underpaid transaction = %+v, want one %s", alerts, FraudAmountMismatch)
+	}
+
+	assertCampaignProgress(t, db, testCampaign.ID, 50000, 1)
+	assertJournalCount(t, db, 2)
+
+	// Receipts go out in the background
+	s.WaitForReceipts(ctx)
+	if sent := recorder.sent(); len(sent) != 1 {
+		t.Errorf("sent %d receipts, want 1", len(sent))
+	}
+
+	// A notification handled with the copy loaded before reconciliation settled it
+	// must not credit the campaign again
+	if _, err := s.applyPaymentStatus(ctx, paid, payment.StatusPaid); err != nil {
+		t.Fatalf("applyPaymentStatus: %v", err)
+	}
+
+	assertCampaignProgress(t, db, testCampaign.ID, 50000, 1)
+	assertJournalCount(t, db, 2)
+
+	s.WaitForReceipts(ctx)
+	if sent := recorder.sent(); len(sent) != 1 {
+		t.Errorf("sent %d receipts after a repeated settlement, want 1", len(sent))
+	}
+}
+
+func TestReconcileChecksLocallyExpiredTransactions(t *testing.T) {
+	statuses := map[string]map[string]any{}
+	newMidtransStub(t, statuses, nil)
+	s, db, _ := newTestService(t)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+	paidLate := createPendingTransaction(t, db, testCampaign.ID, 50000)
+	abandoned := createPendingTransaction(t, db, testCampaign.ID, 20000)
+
+	for _, transaction := range []Transaction{paidLate, abandoned} {
+		transaction.Status = "expired"
+		if _, err := s.repository.UpdateStatus(ctx, transaction, []string{"pending"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The backer paid just after the expiry sweep ran
+	statuses[paidLate.Code] = map[string]any{"transaction_status": "settlement", "gross_amount": "50000.00"}
+
+	report, err := s.Reconcile(ctx, time.Minute)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	s.WaitForReceipts(ctx)
+
+	if report.Checked != 2 || report.Updated != 1 || report.MissingAtProvider != 1 {
+		t.Errorf("report = %d checked, %d updated, %d missing; want 2, 1, 1", report.Checked, report.Updated, report.MissingAtProvider)
+	}
+
+	wantStatuses := map[int]string{paidLate.ID: "paid", abandoned.ID: "cancelled"}
+	for transactionID, want := range wantStatuses {
+		transaction, err := s.repository.GetByID(ctx, transactionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if transaction.Status != want {
+			t.Errorf("status of transaction %d = %q, want %q", transactionID, transaction.Status, want)
+		}
+	}
+
+	assertCampaignProgress(t, db, testCampaign.ID, 50000, 1)
+}
+
+func assertCampaignProgress(t *testing.T, db *gorm.DB, campaignID int, currentAmount int, backerCount int) {
+	t.Helper()
+
+	current, err := campaign.NewRepository(db).FindByID(context.Background(), campaignID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if current.CurrentAmount != currentAmount || current.BackerCount != backerCount {
+		t.Errorf("campaign progress = %d from %d backers, want %d from %d", current.CurrentAmount, current.BackerCount, currentAmount, backerCount)
+	}
+}
+
+func assertJournalCount(t *testing.T, db *gorm.DB, want int64) {
+	t.Helper()
+
+	var count int64
+	if err := db.Model(&ledger.Journal{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if count != want {
+		t.Errorf("ledger has %d journals, want %d", count, want)
+	}
+}
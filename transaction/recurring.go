@@ -0,0 +1,89 @@
+package transaction
+
+import (
+	"backer/logger"
+	"backer/metrics"
+	"backer/payment"
+	"context"
+	"errors"
+)
+
+// ChargeRecurring creates the transaction for one cycle of a recurring pledge and
+// charges the pledge's saved card for it right away.
+func (s *service) ChargeRecurring(ctx context.Context, input ChargeRecurringInput) (Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.ChargeRecurring")
+	defer span.End()
+
+	campaign, err := s.campaignRepository.FindByID(ctx, input.CampaignID)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	if campaign.ID == 0 {
+		return Transaction{}, ErrCampaignNotFound
+	}
+
+	transaction := Transaction{}
+	transaction.CampaignID = input.CampaignID
+	transaction.Amount = input.Amount
+	transaction.UserID = input.User.ID
+	transaction.PledgeID = input.PledgeID
+	transaction.Status = "pending"
+
+	transaction.Provider = input.Provider
+	if transaction.Provider == "" {
+		transaction.Provider = campaign.PaymentProvider
+	}
+	if transaction.Provider == "" {
+		transaction.Provider = s.paymentService.DefaultProvider()
+	}
+
+	transaction.Code, err = generateCode()
+	if err != nil {
+		return transaction, err
+	}
+
+	newTransaction, err := s.repository.Save(ctx, transaction)
+	if err != nil {
+		return newTransaction, err
+	}
+	metrics.TransactionCreated(newTransaction.Provider)
+
+	charge := payment.TokenCharge{
+		OrderID:   orderIDOf(newTransaction),
+		Amount:    newTransaction.Amount,
+		CardToken: input.CardToken,
+		Customer: payment.Customer{
+			Name:  input.User.Name,
+			Email: input.User.Email,
+		},
+	}
+
+	event, err := s.paymentService.ChargeToken(ctx, newTransaction.Provider, charge)
+	if err != nil && chargeNeverMade(err) {
+		// The charge never reached the card, so this cycle's transaction is void
+		cancelledTransaction, cancelErr := s.cancel(context.WithoutCancel(ctx), newTransaction, []string{"pending"})
+		if cancelErr != nil {
+			return cancelledTransaction, cancelErr
+		}
+		return cancelledTransaction, err
+	}
+	if err != nil {
+		// The charge may have gone through, e.g. before a timeout, so the transaction
+		// stays pending for the provider's notification or reconciliation to settle
+		logger.FromContext(ctx).Warn("Recurring charge outcome unknown", "transaction_id", newTransaction.ID, "code", newTransaction.Code, "error", err)
+		return newTransaction, nil
+	}
+
+	// The card was charged, so the outcome is recorded even past the deadline. The
+	// response gets the same checks as a notification before anything is credited.
+	return s.applyEvent(context.WithoutCancel(ctx), newTransaction, event)
+}
+
+// chargeNeverMade tells whether a failed charge certainly did not take any money:
+// the provider turned it down, or it was never sent.
+func chargeNeverMade(err error) bool {
+	return errors.Is(err, payment.ErrChargeRejected) ||
+		errors.Is(err, payment.ErrUnknownProvider) ||
+		errors.Is(err, payment.ErrRecurringNotSupported)
+}
@@ -0,0 +1,99 @@
+package transaction
+
+import (
+	"backer/payment"
+	"backer/user"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newChargeStub answers every Midtrans charge request with statusCode and body.
+func newChargeStub(t *testing.T, statusCode int, body map[string]any) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/charge" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("MIDTRANS_API_URL", server.URL)
+	t.Setenv("MIDTRANS_SERVER_KEY", "test-server-key")
+}
+
+func TestChargeRecurring(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       map[string]any
+		wantErr    error
+		wantStatus string
+	}{
+		{
+			name:       "settled",
+			statusCode: http.StatusOK,
+			body:       map[string]any{"status_code": "200", "transaction_status": "capture", "fraud_status": "accept", "gross_amount": "50000.00", "currency": "IDR", "payment_type": "credit_card"},
+			wantStatus: "paid",
+		},
+		{
+			name:       "charged a different amount",
+			statusCode: http.StatusOK,
+			body:       map[string]any{"status_code": "200", "transaction_status": "capture", "fraud_status": "accept", "gross_amount": "5000.00", "currency": "IDR", "payment_type": "credit_card"},
+			wantStatus: "review",
+		},
+		{
+			name:       "rejected",
+			statusCode: http.StatusOK,
+			body:       map[string]any{"status_code": "411", "status_message": "Token id is missing, invalid, or timed out"},
+			wantErr:    payment.ErrChargeRejected,
+			wantStatus: "cancelled",
+		},
+		{
+			// The card may have been charged before the provider failed
+			name:       "provider error",
+			statusCode: http.StatusBadGateway,
+			body:       map[string]any{"status_code": "502", "status_message": "Bad gateway"},
+			wantStatus: "pending",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newChargeStub(t, test.statusCode, test.body)
+			s, db, _ := newTestService(t)
+			ctx := context.Background()
+
+			testCampaign := createCampaign(t, db)
+
+			charged, err := s.ChargeRecurring(ctx, ChargeRecurringInput{
+				PledgeID:   1,
+				CampaignID: testCampaign.ID,
+				Amount:     50000,
+				CardToken:  "card-token",
+				User:       user.User{ID: 1, Name: "Ann", Email: "ann@example.com"},
+			})
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("ChargeRecurring = %v, want %v", err, test.wantErr)
+			}
+
+			stored, err := s.repository.GetByID(ctx, charged.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != test.wantStatus {
+				t.Errorf("status = %q, want %q", stored.Status, test.wantStatus)
+			}
+
+			s.WaitForReceipts(ctx)
+		})
+	}
+}
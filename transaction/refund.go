@@ -0,0 +1,142 @@
+package transaction
+
+import (
+	"backer/ledger"
+	"backer/logger"
+	"backer/payment"
+	"context"
+	"errors"
+)
+
+// RefundTransaction refunds part or all of a paid transaction. Only admins and the
+// owner of the transaction's campaign may do so. The refund is only marked failed
+// when the provider rejects it; if the request fails in any other way it is returned
+// pending, to be settled by the provider's notification or by reconciliation.
+func (s *service) RefundTransaction(ctx context.Context, inputID GetTransactionInput, inputData CreateRefundInput) (Refund, error) {
+	ctx, span := tracer.Start(ctx, "transaction.RefundTransaction")
+	defer span.End()
+
+	transaction, err := s.repository.GetByID(ctx, inputID.ID)
+	if err != nil {
+		return Refund{}, err
+	}
+
+	if transaction.ID == 0 {
+		return Refund{}, ErrTransactionNotFound
+	}
+
+	campaign, err := s.campaignRepository.FindByID(ctx, transaction.CampaignID)
+	if err != nil {
+		return Refund{}, err
+	}
+
+	if inputData.User.Role != "admin" && campaign.UserID != inputData.User.ID {
+		return Refund{}, ErrNotAuthorized
+	}
+
+	refund := Refund{}
+	refund.TransactionID = transaction.ID
+	refund.RequestedBy = inputData.User.ID
+	refund.Amount = inputData.Amount
+	refund.Reason = inputData.Reason
+	refund.Note = inputData.Note
+	refund.Status = "pending"
+
+	newRefund, err := s.repository.SaveRefundRequest(ctx, refund, []string{"paid"})
+	if err != nil {
+		return newRefund, err
+	}
+
+	return s.requestRefund(ctx, transaction, newRefund)
+}
+
+// requestRefund asks the provider to carry out a reserved refund and completes it
+// if the provider does so right away.
+func (s *service) requestRefund(ctx context.Context, transaction Transaction, newRefund Refund) (Refund, error) {
+	result, err := s.paymentService.Refund(ctx, transaction.Provider, payment.Refund{
+		OrderID:   orderIDOf(transaction),
+		RefundKey: newRefund.RefundKey,
+		Amount:    newRefund.Amount,
+		Reason:    newRefund.Reason,
+	})
+	if errors.Is(err, payment.ErrRefundRejected) {
+		newRefund.Status = "failed"
+		if _, updateErr := s.repository.UpdateRefund(context.WithoutCancel(ctx), newRefund); updateErr != nil {
+			return newRefund, updateErr
+		}
+		return newRefund, err
+	}
+
+	// The provider may still carry out a refund whose request timed out or failed
+	// midway, so it stays pending and keeps holding its amount until the
+	// notification or reconciliation settles it.
+	if err != nil {
+		logger.FromContext(ctx).Warn("Refund outcome unknown, leaving it pending", "refund_id", newRefund.ID, "refund_key", newRefund.RefundKey, "error", err)
+		return newRefund, nil
+	}
+
+	// Otherwise the provider confirms the refund later through a notification.
+	if result.Completed {
+		newRefund, err = s.completeRefund(context.WithoutCancel(ctx), transaction, newRefund)
+		if err != nil {
+			return newRefund, err
+		}
+	}
+
+	return newRefund, nil
+}
+
+// applyRefundNotification completes the pending refunds the provider reports as done
+// and returns how many it completed. Refunds made outside of the API (e.g. from the
+// provider dashboard) are not tracked here.
+func (s *service) applyRefundNotification(ctx context.Context, transaction Transaction, notifiedRefunds []payment.RefundEvent) (int, error) {
+	completed := 0
+
+	refunds, err := s.repository.GetRefundsByTransactionID(ctx, transaction.ID)
+	if err != nil {
+		return completed, err
+	}
+
+	pendingRefunds := map[string]Refund{}
+	for _, refund := range refunds {
+		if refund.Status == "pending" {
+			pendingRefunds[refund.RefundKey] = refund
+		}
+	}
+
+	for _, notifiedRefund := range notifiedRefunds {
+		refund, ok := pendingRefunds[notifiedRefund.RefundKey]
+		if !ok {
+			continue
+		}
+
+		completedRefund, err := s.completeRefund(ctx, transaction, refund)
+		if err != nil {
+			return completed, err
+		}
+
+		if completedRefund.Status == "succeeded" {
+			completed++
+		}
+	}
+
+	return completed, nil
+}
+
+// completeRefund marks a refund as succeeded and takes its amount back out of the
+// transaction and the campaign's progress, see Repository.CompleteRefund. A refund
+// that was already completed is returned as it is.
+func (s *service) completeRefund(ctx context.Context, transaction Transaction, refund Refund) (Refund, error) {
+	journal := ledger.RefundJournal(transaction.CampaignID, refund.RefundKey, refund.Amount)
+
+	completed, err := s.repository.CompleteRefund(ctx, refund, journal)
+	if err != nil {
+		return refund, err
+	}
+
+	if completed {
+		refund.Status = "succeeded"
+	}
+
+	return refund, nil
+}
@@ -0,0 +1,223 @@
+package transaction
+
+import (
+	"backer/payment"
+	"backer/user"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+var admin = user.User{ID: 1, Role: "admin"}
+
+func createPaidTransaction(t *testing.T, s *service, db *gorm.DB, campaignID int, amount int) Transaction {
+	t.Helper()
+
+	transaction := createPendingTransaction(t, db, campaignID, amount)
+
+	paidTransaction, err := s.applyPaymentStatus(context.Background(), transaction, payment.StatusPaid)
+	if err != nil {
+		t.Fatalf("applyPaymentStatus: %v", err)
+	}
+
+	return paidTransaction
+}
+
+func refund(s *service, transactionID int, amount int) (Refund, error) {
+	return s.RefundTransaction(context.Background(), GetTransactionInput{ID: transactionID}, CreateRefundInput{
+		Amount: amount,
+		Reason: "requested_by_customer",
+		User:   admin,
+	})
+}
+
+func TestRefundTransaction(t *testing.T) {
+	statuses := map[string]map[string]any{}
+	refunds := map[string]int{}
+	newMidtransStub(t, statuses, refunds)
+	s, db, _ := newTestService(t)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+	transaction := createPaidTransaction(t, s, db, testCampaign.ID, 50000)
+
+	refunds[transaction.Code] = http.StatusOK
+
+	completed, err := refund(s, transaction.ID, 20000)
+	if err != nil {
+		t.Fatalf("refunding 20000: %v", err)
+	}
+	if completed.Status != "succeeded" {
+		t.Errorf("status of a refund the provider completed = %q, want succeeded", completed.Status)
+	}
+	assertCampaignProgress(t, db, testCampaign.ID, 30000, 1)
+	assertJournalCount(t, db, 3)
+
+	if _, err := refund(s, transaction.ID, 40000); !errors.Is(err, ErrRefundAmountExceeded) {
+		t.Errorf("refunding more than what is left = %v, want %v", err, ErrRefundAmountExceeded)
+	}
+
+	// A provider error leaves the outcome unknown, so the refund keeps its amount
+	refunds[transaction.Code] = http.StatusInternalServerError
+
+	unknown, err := refund(s, transaction.ID, 10000)
+	if err != nil {
+		t.Fatalf("refunding 10000 with the provider failing: %v", err)
+	}
+	if unknown.Status != "pending" {
+		t.Errorf("status of a refund with an unknown outcome = %q, want pending", unknown.Status)
+	}
+
+	if _, err := refund(s, transaction.ID, 25000); !errors.Is(err, ErrRefundAmountExceeded) {
+		t.Errorf("refunding the amount held by a pending refund = %v, want %v", err, ErrRefundAmountExceeded)
+	}
+
+	// A rejection is final and gives the amount back
+	refunds[transaction.Code] = http.StatusPreconditionFailed
+
+	rejected, err := refund(s, transaction.ID, 20000)
+	if !errors.Is(err, payment.ErrRefundRejected) {
+		t.Fatalf("refunding with the provider rejecting = %v, want %v", err, payment.ErrRefundRejected)
+	}
+	if rejected.Status != "failed" {
+		t.Errorf("status of a rejected refund = %q, want failed", rejected.Status)
+	}
+	assertCampaignProgress(t, db, testCampaign.ID, 30000, 1)
+
+	// Reconciliation completes the pending refund once the provider lists it
+	statuses[transaction.Code] = map[string]any{
+		"transaction_status": "partial_refund",
+		"gross_amount":       "50000.00",
+		"refunds":            []map[string]string{{"refund_key": unknown.RefundKey, "refund_amount": "10000.00"}},
+	}
+
+	report, err := s.Reconcile(ctx, 0)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if report.Updated != 1 || len(report.Discrepancies) != 1 || report.Discrepancies[0].Kind != DiscrepancyRefundedAtProvider {
+		t.Errorf("report = %+v, want one %s discrepancy", report, DiscrepancyRefundedAtProvider)
+	}
+
+	stored, err := s.repository.GetByID(ctx, transaction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.RefundedAmount != 30000 || stored.Status != "paid" {
+		t.Errorf("transaction = %d refunded and %q, want 30000 and paid", stored.RefundedAmount, stored.Status)
+	}
+	assertCampaignProgress(t, db, testCampaign.ID, 20000, 1)
+	assertJournalCount(t, db, 4)
+
+	// The notification for the same refund arriving late changes nothing
+	if _, err := s.applyRefundNotification(ctx, stored, []payment.RefundEvent{{RefundKey: unknown.RefundKey, Amount: "10000.00"}}); err != nil {
+		t.Fatalf("applyRefundNotification: %v", err)
+	}
+	assertCampaignProgress(t, db, testCampaign.ID, 20000, 1)
+	assertJournalCount(t, db, 4)
+}
+
+func TestRefundTransactionConcurrently(t *testing.T) {
+	refunds := map[string]int{}
+	newMidtransStub(t, nil, refunds)
+	s, db, _ := newTestService(t)
+
+	testCampaign := createCampaign(t, db)
+	transaction := createPaidTransaction(t, s, db, testCampaign.ID, 50000)
+	refunds[transaction.Code] = http.StatusOK
+
+	var wg sync.WaitGroup
+	results := make([]Refund, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Losers fail one way or another, so only the outcome is checked
+			results[i], _ = refund(s, transaction.ID, 0)
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Status == "succeeded" {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d full refunds succeeded, want 1", succeeded)
+	}
+
+	stored, err := s.repository.GetByID(context.Background(), transaction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.RefundedAmount != 50000 || stored.Status != "refunded" {
+		t.Errorf("transaction = %d refunded and %q, want 50000 and refunded", stored.RefundedAmount, stored.Status)
+	}
+	assertCampaignProgress(t, db, testCampaign.ID, 0, 0)
+}
+
+func TestRejectingAFraudAlertRefundsThePayment(t *testing.T) {
+	refunds := map[string]int{}
+	newMidtransStub(t, nil, refunds)
+	s, db, _ := newTestService(t)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+	underpaid := createPendingTransaction(t, db, testCampaign.ID, 30000)
+
+	event := payment.Event{OrderID: underpaid.Code, Status: payment.StatusPaid, GrossAmount: "3000.00"}
+	if _, err := s.applyEvent(ctx, underpaid, event); err != nil {
+		t.Fatalf("applyEvent: %v", err)
+	}
+
+	alerts, err := s.repository.GetOpenFraudAlertsByTransactionID(ctx, underpaid.ID)
+	if err != nil || len(alerts) != 1 {
+		t.Fatalf("fraud alerts = %+v, %v; want one", alerts, err)
+	}
+
+	reject := func() error {
+		_, err := s.ResolveFraudAlert(ctx, GetFraudAlertInput{ID: alerts[0].ID}, ResolveFraudAlertInput{Action: "reject", Note: "card testing", User: admin})
+		return err
+	}
+
+	// The provider turns the refund down the first time, so the alert stays open
+	if err := reject(); !errors.Is(err, payment.ErrRefundRejected) {
+		t.Fatalf("rejecting with the refund rejected = %v, want %v", err, payment.ErrRefundRejected)
+	}
+
+	refunds[underpaid.Code] = http.StatusOK
+
+	if err := reject(); err != nil {
+		t.Fatalf("rejecting again: %v", err)
+	}
+
+	if err := reject(); !errors.Is(err, ErrFraudAlertResolved) {
+		t.Errorf("rejecting a resolved alert = %v, want %v", err, ErrFraudAlertResolved)
+	}
+
+	stored, err := s.repository.GetByID(ctx, underpaid.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != "cancelled" || stored.RefundedAmount != 3000 {
+		t.Errorf("transaction = %q with %d refunded, want cancelled with the 3000 captured", stored.Status, stored.RefundedAmount)
+	}
+
+	storedRefunds, err := s.repository.GetRefundsByTransactionID(ctx, underpaid.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storedRefunds) != 2 || storedRefunds[0].Status != "failed" || storedRefunds[1].Status != "succeeded" || storedRefunds[1].Amount != 3000 {
+		t.Errorf("refunds = %+v, want a failed one and a succeeded one of 3000", storedRefunds)
+	}
+
+	// The campaign was never credited, so there is nothing to take back
+	assertCampaignProgress(t, db, testCampaign.ID, 0, 0)
+	assertJournalCount(t, db, 0)
+}
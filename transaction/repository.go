@@ -1,28 +1,62 @@
 package transaction
 
-import "gorm.io/gorm"
+import (
+	"backer/ledger"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 type repository struct {
 	db *gorm.DB
 }
 
 type Repository interface {
-	GetByCampaignID(campaignID int) ([]Transaction, error)
-	GetByUserID(userID int) ([]Transaction, error)
-	GetByID(ID int) (Transaction, error)
-	Save(transaction Transaction) (Transaction, error)
-	Update(transaction Transaction) (Transaction, error)
-	GetByCode(code string) (Transaction, error)
+	GetByCampaignID(ctx context.Context, campaignID int) ([]Transaction, error)
+	GetByUserID(ctx context.Context, userID int) ([]Transaction, error)
+	GetByID(ctx context.Context, ID int) (Transaction, error)
+	Save(ctx context.Context, transaction Transaction) (Transaction, error)
+	Update(ctx context.Context, transaction Transaction) (Transaction, error)
+	UpdateStatus(ctx context.Context, transaction Transaction, from []string) (bool, error)
+	UpdateMessageStatus(ctx context.Context, transaction Transaction) error
+	UpdateClaimToken(ctx context.Context, transaction Transaction) error
+	SettlePaid(ctx context.Context, transaction Transaction, from []string, journals []ledger.Journal) (bool, error)
+	GetByCode(ctx context.Context, code string) (Transaction, error)
+	GetOpenBefore(ctx context.Context, statuses []string, cutoff time.Time) ([]Transaction, error)
+	ExpirePendingBefore(ctx context.Context, now time.Time) (int64, error)
+	GetWithPendingRefundsBefore(ctx context.Context, cutoff time.Time) ([]Transaction, error)
+	GetRefundsByTransactionID(ctx context.Context, transactionID int) ([]Refund, error)
+	SaveRefundRequest(ctx context.Context, refund Refund, from []string) (Refund, error)
+	UpdateRefund(ctx context.Context, refund Refund) (Refund, error)
+	CompleteRefund(ctx context.Context, refund Refund, journal ledger.Journal) (bool, error)
+	GetMessagesByCampaignID(ctx context.Context, campaignID int, limit int) ([]Transaction, error)
+	GetBackersByCampaignID(ctx context.Context, campaignID int, limit int, offset int) ([]Transaction, int64, error)
+	GetTopBackersByCampaignID(ctx context.Context, campaignID int, limit int) ([]BackerTotal, error)
+	GetByClaimTokenHash(ctx context.Context, tokenHash string) (Transaction, error)
+	ClaimGuestTransactions(ctx context.Context, guestEmail string, userID int) (int64, error)
+	GetDetailsByID(ctx context.Context, ID int) (Transaction, error)
+	GetReceiptByTransactionID(ctx context.Context, transactionID int) (Receipt, error)
+	SaveReceipt(ctx context.Context, receipt Receipt, number func(receipt Receipt) string) (Receipt, error)
+	EachByCampaignID(ctx context.Context, filter ExportFilter, fn func(transactions []Transaction) error) error
+	HoldForReview(ctx context.Context, transaction Transaction, from []string, alerts []FraudAlert) (bool, error)
+	UpdateFraudAlert(ctx context.Context, alert FraudAlert) (FraudAlert, error)
+	GetFraudAlerts(ctx context.Context, status string) ([]FraudAlert, error)
+	GetFraudAlertByID(ctx context.Context, ID int) (FraudAlert, error)
+	GetOpenFraudAlertsByTransactionID(ctx context.Context, transactionID int) ([]FraudAlert, error)
 }
 
 func NewRepository(db *gorm.DB) *repository {
 	return &repository{db}
 }
 
-func (r *repository) GetByCampaignID(campaignID int) ([]Transaction, error) {
+func (r *repository) GetByCampaignID(ctx context.Context, campaignID int) ([]Transaction, error) {
 	var transactions []Transaction
 
-	err := r.db.Preload("User").Preload("Campaign.CampaignImages", "campaign_images.is_primary = 1").Where("campaign_id = ? AND status = ?", campaignID, "paid").Order("id desc").Find(&transactions).Error
+	err := r.db.WithContext(ctx).Preload("User").Preload("Campaign.CampaignImages", "campaign_images.is_primary = ?", 1).Where("campaign_id = ? AND status = ?", campaignID, "paid").Order("id desc").Find(&transactions).Error
 	if err != nil {
 		return transactions, err
 	}
@@ -30,10 +64,10 @@ func (r *repository) GetByCampaignID(campaignID int) ([]Transaction, error) {
 	return transactions, nil
 }
 
-func (r *repository) GetByUserID(userID int) ([]Transaction, error) {
+func (r *repository) GetByUserID(ctx context.Context, userID int) ([]Transaction, error) {
 	var transactions []Transaction
 
-	err := r.db.Preload("Campaign.CampaignImages", "campaign_images.is_primary = 1").Where("user_id = ?", userID).Order("id desc").Find(&transactions).Error
+	err := r.db.WithContext(ctx).Preload("Campaign.CampaignImages", "campaign_images.is_primary = ?", 1).Where("user_id = ? AND status <> ?", userID, "expired").Order("id desc").Find(&transactions).Error
 	if err != nil {
 		return transactions, err
 	}
@@ -41,10 +75,10 @@ func (r *repository) GetByUserID(userID int) ([]Transaction, error) {
 	return transactions, nil
 }
 
-func (r *repository) GetByID(ID int) (Transaction, error) {
+func (r *repository) GetByID(ctx context.Context, ID int) (Transaction, error) {
 	var transaction Transaction
 
-	err := r.db.Where("id = ?", ID).Find(&transaction).Error
+	err := r.db.WithContext(ctx).Where("id = ?", ID).Find(&transaction).Error
 	if err != nil {
 		return transaction, err
 	}
@@ -52,8 +86,8 @@ func (r *repository) GetByID(ID int) (Transaction, error) {
 	return transaction, nil
 }
 
-func (r *repository) Save(transaction Transaction) (Transaction, error) {
-	err := r.db.Create(&transaction).Error
+func (r *repository) Save(ctx context.Context, transaction Transaction) (Transaction, error) {
+	err := r.db.WithContext(ctx).Create(&transaction).Error
 	if err != nil {
 		return transaction, err
 	}
@@ -61,8 +95,8 @@ func (r *repository) Save(transaction Transaction) (Transaction, error) {
 	return transaction, nil
 }
 
-func (r *repository) Update(transaction Transaction) (Transaction, error) {
-	err := r.db.Save(&transaction).Error
+func (r *repository) Update(ctx context.Context, transaction Transaction) (Transaction, error) {
+	err := r.db.WithContext(ctx).Save(&transaction).Error
 	if err != nil {
 		return transaction, err
 	}
@@ -70,13 +104,538 @@ func (r *repository) Update(transaction Transaction) (Transaction, error) {
 	return transaction, nil
 }
 
-func (r *repository) GetByCode(code string) (Transaction, error) {
+// UpdateMessageStatus saves only the moderation status of a transaction's message,
+// so it never writes back a stale copy of the payment status or amounts.
+func (r *repository) UpdateMessageStatus(ctx context.Context, transaction Transaction) error {
+	return r.db.WithContext(ctx).Model(&Transaction{}).
+		Where("id = ?", transaction.ID).
+		Update("message_status", transaction.MessageStatus).Error
+}
+
+// UpdateClaimToken saves only the claim token of a guest transaction. Receipts are
+// sent in the background, so a full save could undo a refund or claim made meanwhile.
+func (r *repository) UpdateClaimToken(ctx context.Context, transaction Transaction) error {
+	return r.db.WithContext(ctx).Model(&Transaction{}).
+		Where("id = ?", transaction.ID).
+		Updates(map[string]interface{}{
+			"claim_token_hash":       transaction.ClaimTokenHash,
+			"claim_token_expires_at": transaction.ClaimTokenExpiresAt,
+		}).Error
+}
+
+// UpdateStatus moves a transaction to transaction.Status as long as it is still in
+// one of the from statuses, and reports whether it did. Notifications,
+// reconciliation and admins can act on the same transaction at once, so a status is
+// never overwritten from a stale copy.
+func (r *repository) UpdateStatus(ctx context.Context, transaction Transaction, from []string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Transaction{}).
+		Where("id = ? AND status IN ?", transaction.ID, from).
+		Update("status", transaction.Status)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// SettlePaid marks a transaction paid if it is still in one of the from statuses
+// and, in the same database transaction, credits its campaign and posts its ledger
+// journals. It reports false without changing anything when another caller got to
+// the transaction first. A failure rolls everything back, so a retried notification
+// finds the transaction unsettled and settles it again.
+func (r *repository) SettlePaid(ctx context.Context, transaction Transaction, from []string, journals []ledger.Journal) (bool, error) {
+	settled := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Transaction{}).
+			Where("id = ? AND status IN ?", transaction.ID, from).
+			Updates(map[string]interface{}{"status": "paid", "paid_at": transaction.PaidAt})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected != 1 {
+			return nil
+		}
+
+		newBackers := 0
+		isNewBacker, err := countsAsNewBacker(tx, transaction)
+		if err != nil {
+			return err
+		}
+
+		if isNewBacker {
+			newBackers = 1
+		}
+
+		err = tx.Table("campaigns").Where("id = ?", transaction.CampaignID).Updates(map[string]interface{}{
+			"current_amount": gorm.Expr("current_amount + ?", transaction.Amount),
+			"backer_count":   gorm.Expr("backer_count + ?", newBackers),
+			"updated_at":     time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := ledger.PostJournals(tx, journals); err != nil {
+			return err
+		}
+
+		settled = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return settled, nil
+}
+
+// countsAsNewBacker tells whether a paid transaction brings a new backer to its
+// campaign. Of a recurring pledge, only the first paid cycle does.
+func countsAsNewBacker(tx *gorm.DB, transaction Transaction) (bool, error) {
+	if transaction.PledgeID == 0 {
+		return true, nil
+	}
+
+	var firstPaidID int
+	err := tx.Model(&Transaction{}).Select("id").
+		Where("pledge_id = ? AND status IN ?", transaction.PledgeID, []string{"paid", "refunded"}).
+		Order("id asc").Limit(1).Scan(&firstPaidID).Error
+	if err != nil {
+		return false, err
+	}
+
+	return firstPaidID == transaction.ID, nil
+}
+
+func (r *repository) GetByCode(ctx context.Context, code string) (Transaction, error) {
 	var transaction Transaction
 
-	err := r.db.Where("code = ?", code).Find(&transaction).Error
+	err := r.db.WithContext(ctx).Where("code = ?", code).Find(&transaction).Error
 	if err != nil {
 		return transaction, err
 	}
 
 	return transaction, nil
 }
+
+// GetOpenBefore returns the transactions in one of statuses that were created before
+// cutoff, oldest first.
+func (r *repository) GetOpenBefore(ctx context.Context, statuses []string, cutoff time.Time) ([]Transaction, error) {
+	var transactions []Transaction
+
+	err := r.db.WithContext(ctx).Where("status IN ? AND created_at < ?", statuses, cutoff).Order("id asc").Find(&transactions).Error
+	if err != nil {
+		return transactions, err
+	}
+
+	return transactions, nil
+}
+
+func (r *repository) ExpirePendingBefore(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&Transaction{}).Where("status = ? AND expires_at IS NOT NULL AND expires_at < ?", "pending", now).Update("status", "expired")
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// GetWithPendingRefundsBefore returns the transactions with a refund that has been
+// pending since before cutoff.
+func (r *repository) GetWithPendingRefundsBefore(ctx context.Context, cutoff time.Time) ([]Transaction, error) {
+	var transactions []Transaction
+
+	pendingRefunds := r.db.Model(&Refund{}).Select("transaction_id").Where("status = ? AND created_at < ?", "pending", cutoff)
+
+	err := r.db.WithContext(ctx).Where("id IN (?)", pendingRefunds).Order("id asc").Find(&transactions).Error
+	if err != nil {
+		return transactions, err
+	}
+
+	return transactions, nil
+}
+
+func (r *repository) GetRefundsByTransactionID(ctx context.Context, transactionID int) ([]Refund, error) {
+	var refunds []Refund
+
+	err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).Order("id asc").Find(&refunds).Error
+	if err != nil {
+		return refunds, err
+	}
+
+	return refunds, nil
+}
+
+// SaveRefundRequest reserves a refund of a transaction in one of the from statuses,
+// normally "paid". Pending refunds hold their amount too, and the transaction row is
+// locked while the refundable amount is worked out, so concurrent requests can't both
+// refund the same money; SQLite has no row locks but only ever runs one writer. A
+// zero amount refunds what is left.
+func (r *repository) SaveRefundRequest(ctx context.Context, refund Refund, from []string) (Refund, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var transaction Transaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", refund.TransactionID).Find(&transaction).Error
+		if err != nil {
+			return err
+		}
+
+		if transaction.ID == 0 {
+			return ErrTransactionNotFound
+		}
+
+		if !slices.Contains(from, transaction.Status) {
+			return ErrTransactionNotRefundable
+		}
+
+		var reserved int
+		err = tx.Model(&Refund{}).
+			Select("COALESCE(SUM(amount), 0)").
+			Where("transaction_id = ? AND status <> ?", transaction.ID, "failed").
+			Scan(&reserved).Error
+		if err != nil {
+			return err
+		}
+
+		refundable := transaction.Amount - reserved
+		if refundable <= 0 {
+			return ErrTransactionNotRefundable
+		}
+
+		if refund.Amount == 0 {
+			refund.Amount = refundable
+		}
+
+		if refund.Amount > refundable {
+			return ErrRefundAmountExceeded
+		}
+
+		err = tx.Create(&refund).Error
+		if err != nil {
+			return err
+		}
+
+		refund.RefundKey = fmt.Sprintf("%s-refund-%d", orderIDOf(transaction), refund.ID)
+
+		return tx.Model(&refund).Update("refund_key", refund.RefundKey).Error
+	})
+	if err != nil {
+		return refund, err
+	}
+
+	return refund, nil
+}
+
+func (r *repository) UpdateRefund(ctx context.Context, refund Refund) (Refund, error) {
+	err := r.db.WithContext(ctx).Save(&refund).Error
+	if err != nil {
+		return refund, err
+	}
+
+	return refund, nil
+}
+
+// CompleteRefund marks a pending refund succeeded and, in the same database
+// transaction, takes its amount back out of the transaction and the campaign's
+// progress and posts its ledger journal. A fully refunded transaction no longer counts
+// as a backer. Refunds of a payment rejected after review only update the refunded
+// amount. It reports false without changing anything when the refund was already
+// completed, e.g. by the provider's notification racing the refund request.
+func (r *repository) CompleteRefund(ctx context.Context, refund Refund, journal ledger.Journal) (bool, error) {
+	completed := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Refund{}).
+			Where("id = ? AND status = ?", refund.ID, "pending").
+			Updates(map[string]interface{}{"status": "succeeded", "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected != 1 {
+			return nil
+		}
+
+		var transaction Transaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", refund.TransactionID).Find(&transaction).Error
+		if err != nil {
+			return err
+		}
+
+		transaction.RefundedAmount = transaction.RefundedAmount + refund.Amount
+
+		// A payment rejected after review was never credited, so there is nothing to
+		// take back from the campaign or the ledger
+		if transaction.Status == "cancelled" {
+			completed = true
+			return tx.Model(&transaction).Update("refunded_amount", transaction.RefundedAmount).Error
+		}
+
+		if transaction.RefundedAmount >= transaction.Amount {
+			transaction.Status = "refunded"
+		}
+
+		err = tx.Model(&transaction).Updates(map[string]interface{}{
+			"refunded_amount": transaction.RefundedAmount,
+			"status":          transaction.Status,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		lostBackers := 0
+		if transaction.Status == "refunded" {
+			isNewBacker, err := countsAsNewBacker(tx, transaction)
+			if err != nil {
+				return err
+			}
+
+			if isNewBacker {
+				lostBackers = 1
+			}
+		}
+
+		err = tx.Table("campaigns").Where("id = ?", transaction.CampaignID).Updates(map[string]interface{}{
+			"current_amount": gorm.Expr("current_amount - ?", refund.Amount),
+			"backer_count":   gorm.Expr("backer_count - ?", lostBackers),
+			"updated_at":     time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := ledger.PostJournals(tx, []ledger.Journal{journal}); err != nil {
+			return err
+		}
+
+		completed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return completed, nil
+}
+
+func (r *repository) GetMessagesByCampaignID(ctx context.Context, campaignID int, limit int) ([]Transaction, error) {
+	var transactions []Transaction
+
+	err := r.db.WithContext(ctx).Preload("User").Where("campaign_id = ? AND status = ? AND message_status = ?", campaignID, "paid", "published").Order("id desc").Limit(limit).Find(&transactions).Error
+	if err != nil {
+		return transactions, err
+	}
+
+	return transactions, nil
+}
+
+func (r *repository) GetBackersByCampaignID(ctx context.Context, campaignID int, limit int, offset int) ([]Transaction, int64, error) {
+	var transactions []Transaction
+	var total int64
+
+	err := r.db.WithContext(ctx).Model(&Transaction{}).Where("campaign_id = ? AND status = ?", campaignID, "paid").Count(&total).Error
+	if err != nil {
+		return transactions, total, err
+	}
+
+	err = r.db.WithContext(ctx).Preload("User").Where("campaign_id = ? AND status = ?", campaignID, "paid").Order("id desc").Limit(limit).Offset(offset).Find(&transactions).Error
+	if err != nil {
+		return transactions, total, err
+	}
+
+	return transactions, total, nil
+}
+
+// GetTopBackersByCampaignID sums paid transactions per backer in the database.
+// Anonymous and named giving of the same user are kept apart so the leaderboard
+// never ties an anonymous amount to a name.
+func (r *repository) GetTopBackersByCampaignID(ctx context.Context, campaignID int, limit int) ([]BackerTotal, error) {
+	var totals []BackerTotal
+
+	err := r.db.WithContext(ctx).Model(&Transaction{}).
+		Select("transactions.user_id, transactions.is_anonymous, COALESCE(users.name, transactions.guest_name) AS name, COALESCE(users.avatar_file_name, '') AS avatar_file_name, SUM(transactions.amount - transactions.refunded_amount) AS total_amount, COUNT(*) AS backing_count").
+		Joins("LEFT JOIN users ON users.id = transactions.user_id").
+		Where("transactions.campaign_id = ? AND transactions.status = ?", campaignID, "paid").
+		Group("transactions.user_id, transactions.guest_email, transactions.guest_name, transactions.is_anonymous, users.name, users.avatar_file_name").
+		Order("total_amount DESC").
+		Limit(limit).
+		Scan(&totals).Error
+	if err != nil {
+		return totals, err
+	}
+
+	return totals, nil
+}
+
+func (r *repository) GetByClaimTokenHash(ctx context.Context, tokenHash string) (Transaction, error) {
+	var transaction Transaction
+
+	err := r.db.WithContext(ctx).Where("claim_token_hash = ? AND user_id = ?", tokenHash, 0).Limit(1).Find(&transaction).Error
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+// ClaimGuestTransactions moves every unclaimed guest transaction made with
+// guestEmail to the given user.
+func (r *repository) ClaimGuestTransactions(ctx context.Context, guestEmail string, userID int) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&Transaction{}).
+		Where("user_id = ? AND guest_email = ?", 0, guestEmail).
+		Updates(map[string]interface{}{"user_id": userID, "claim_token_hash": "", "claim_token_expires_at": nil})
+
+	return result.RowsAffected, result.Error
+}
+
+// GetDetailsByID loads a transaction with its backer and campaign, for reading only.
+func (r *repository) GetDetailsByID(ctx context.Context, ID int) (Transaction, error) {
+	var transaction Transaction
+
+	err := r.db.WithContext(ctx).Preload("User").Preload("Campaign").Where("id = ?", ID).Find(&transaction).Error
+	if err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+func (r *repository) GetReceiptByTransactionID(ctx context.Context, transactionID int) (Receipt, error) {
+	var receipt Receipt
+
+	err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).Limit(1).Find(&receipt).Error
+	if err != nil {
+		return receipt, err
+	}
+
+	return receipt, nil
+}
+
+// SaveReceipt inserts a receipt and numbers it from its ID in the same database
+// transaction, so no receipt is ever left without a number. Until the number is
+// set the row carries a placeholder that is unique to its transaction.
+func (r *repository) SaveReceipt(ctx context.Context, receipt Receipt, number func(receipt Receipt) string) (Receipt, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		receipt.Number = fmt.Sprintf("unnumbered-%d", receipt.TransactionID)
+		if err := tx.Create(&receipt).Error; err != nil {
+			return err
+		}
+
+		receipt.Number = number(receipt)
+		receipt.FileName = receipt.Number + ".pdf"
+
+		return tx.Model(&receipt).Updates(map[string]interface{}{"number": receipt.Number, "file_name": receipt.FileName}).Error
+	})
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	return receipt, nil
+}
+
+// EachByCampaignID walks a campaign's transactions matching filter in batches,
+// oldest first, so exports never hold a whole campaign in memory.
+func (r *repository) EachByCampaignID(ctx context.Context, filter ExportFilter, fn func(transactions []Transaction) error) error {
+	var transactions []Transaction
+
+	query := r.db.WithContext(ctx).Preload("User").Where("campaign_id = ?", filter.CampaignID)
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+
+	return query.FindInBatches(&transactions, exportBatchSize, func(tx *gorm.DB, batch int) error {
+		return fn(transactions)
+	}).Error
+}
+
+// HoldForReview moves a transaction to "review" if it is still in one of the from
+// statuses and, in the same database transaction, records its fraud alerts. It
+// reports false without recording anything when the transaction has already moved
+// on, so a repeated notification does not raise the same alerts again.
+func (r *repository) HoldForReview(ctx context.Context, transaction Transaction, from []string, alerts []FraudAlert) (bool, error) {
+	held := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Transaction{}).
+			Where("id = ? AND status IN ?", transaction.ID, from).
+			Update("status", "review")
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected != 1 {
+			return nil
+		}
+
+		if len(alerts) > 0 {
+			if err := tx.Omit("Transaction").Create(&alerts).Error; err != nil {
+				return err
+			}
+		}
+
+		held = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return held, nil
+}
+
+func (r *repository) UpdateFraudAlert(ctx context.Context, alert FraudAlert) (FraudAlert, error) {
+	err := r.db.WithContext(ctx).Omit("Transaction").Save(&alert).Error
+	if err != nil {
+		return alert, err
+	}
+
+	return alert, nil
+}
+
+func (r *repository) GetFraudAlerts(ctx context.Context, status string) ([]FraudAlert, error) {
+	var alerts []FraudAlert
+
+	query := r.db.WithContext(ctx).Preload("Transaction")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	err := query.Order("id desc").Find(&alerts).Error
+	if err != nil {
+		return alerts, err
+	}
+
+	return alerts, nil
+}
+
+func (r *repository) GetFraudAlertByID(ctx context.Context, ID int) (FraudAlert, error) {
+	var alert FraudAlert
+
+	err := r.db.WithContext(ctx).Preload("Transaction").Where("id = ?", ID).Find(&alert).Error
+	if err != nil {
+		return alert, err
+	}
+
+	return alert, nil
+}
+
+func (r *repository) GetOpenFraudAlertsByTransactionID(ctx context.Context, transactionID int) ([]FraudAlert, error) {
+	var alerts []FraudAlert
+
+	err := r.db.WithContext(ctx).Where("transaction_id = ? AND status = ?", transactionID, "open").Find(&alerts).Error
+	if err != nil {
+		return alerts, err
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,296 @@
+package transaction
+
+import (
+	"backer/database/databasetest"
+	"backer/ledger"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRepositoryUpdateStatus(t *testing.T) {
+	db := databasetest.Open(t)
+	r := NewRepository(db)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+	transaction := createPendingTransaction(t, db, testCampaign.ID, 50000)
+
+	transaction.Status = "cancelled"
+	updated, err := r.UpdateStatus(ctx, transaction, []string{"pending"})
+	if err != nil || !updated {
+		t.Fatalf("UpdateStatus = %v, %v; want true", updated, err)
+	}
+
+	// A stale copy can't move the transaction on again
+	transaction.Status = "expired"
+	updated, err = r.UpdateStatus(ctx, transaction, []string{"pending"})
+	if err != nil || updated {
+		t.Fatalf("UpdateStatus of a stale copy = %v, %v; want false", updated, err)
+	}
+
+	stored, err := r.GetByID(ctx, transaction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != "cancelled" {
+		t.Errorf("status = %q, want cancelled", stored.Status)
+	}
+}
+
+func TestRepositorySettlePaidCountsPledgeBackersOnce(t *testing.T) {
+	db := databasetest.Open(t)
+	r := NewRepository(db)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+
+	for cycle := range 2 {
+		transaction := createPendingTransaction(t, db, testCampaign.ID, 50000)
+		transaction.PledgeID = 3
+		if _, err := r.Update(ctx, transaction); err != nil {
+			t.Fatal(err)
+		}
+
+		journals := ledger.DonationJournals(testCampaign.ID, transaction.Code, transaction.Amount)
+
+		settled, err := r.SettlePaid(ctx, transaction, openStatuses, journals)
+		if err != nil || !settled {
+			t.Fatalf("SettlePaid of cycle %d = %v, %v; want true", cycle, settled, err)
+		}
+
+		settled, err = r.SettlePaid(ctx, transaction, openStatuses, journals)
+		if err != nil || settled {
+			t.Fatalf("settling cycle %d again = %v, %v; want false", cycle, settled, err)
+		}
+	}
+
+	assertCampaignProgress(t, db, testCampaign.ID, 100000, 1)
+	assertJournalCount(t, db, 4)
+}
+
+func TestRepositoryRefunds(t *testing.T) {
+	db := databasetest.Open(t)
+	r := NewRepository(db)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+	transaction := createPendingTransaction(t, db, testCampaign.ID, 50000)
+
+	if _, err := r.SaveRefundRequest(ctx, Refund{TransactionID: transaction.ID, Status: "pending"}, []string{"paid"}); !errors.Is(err, ErrTransactionNotRefundable) {
+		t.Errorf("refunding a pending transaction = %v, want %v", err, ErrTransactionNotRefundable)
+	}
+
+	if _, err := r.SaveRefundRequest(ctx, Refund{TransactionID: transaction.ID + 100, Status: "pending"}, []string{"paid"}); !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("refunding a missing transaction = %v, want %v", err, ErrTransactionNotFound)
+	}
+
+	settled, err := r.SettlePaid(ctx, transaction, openStatuses, nil)
+	if err != nil || !settled {
+		t.Fatalf("SettlePaid = %v, %v; want true", settled, err)
+	}
+
+	partial, err := r.SaveRefundRequest(ctx, Refund{TransactionID: transaction.ID, Amount: 20000, Status: "pending"}, []string{"paid"})
+	if err != nil {
+		t.Fatalf("SaveRefundRequest: %v", err)
+	}
+	if partial.RefundKey != transaction.Code+"-refund-1" {
+		t.Errorf("refund key = %q, want %q", partial.RefundKey, transaction.Code+"-refund-1")
+	}
+
+	// A zero amount takes whatever the pending refund leaves
+	rest, err := r.SaveRefundRequest(ctx, Refund{TransactionID: transaction.ID, Status: "pending"}, []string{"paid"})
+	if err != nil {
+		t.Fatalf("SaveRefundRequest: %v", err)
+	}
+	if rest.Amount != 30000 {
+		t.Errorf("amount of the remaining refund = %d, want 30000", rest.Amount)
+	}
+
+	if _, err := r.SaveRefundRequest(ctx, Refund{TransactionID: transaction.ID, Amount: 1, Status: "pending"}, []string{"paid"}); !errors.Is(err, ErrTransactionNotRefundable) {
+		t.Errorf("refunding a fully reserved transaction = %v, want %v", err, ErrTransactionNotRefundable)
+	}
+
+	for _, refund := range []Refund{partial, rest} {
+		journal := ledger.RefundJournal(testCampaign.ID, refund.RefundKey, refund.Amount)
+
+		completed, err := r.CompleteRefund(ctx, refund, journal)
+		if err != nil || !completed {
+			t.Fatalf("CompleteRefund of %s = %v, %v; want true", refund.RefundKey, completed, err)
+		}
+
+		completed, err = r.CompleteRefund(ctx, refund, journal)
+		if err != nil || completed {
+			t.Fatalf("completing %s again = %v, %v; want false", refund.RefundKey, completed, err)
+		}
+	}
+
+	stored, err := r.GetByID(ctx, transaction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != "refunded" || stored.RefundedAmount != 50000 {
+		t.Errorf("transaction = %q with %d refunded, want refunded with 50000", stored.Status, stored.RefundedAmount)
+	}
+
+	assertCampaignProgress(t, db, testCampaign.ID, 0, 0)
+	assertJournalCount(t, db, 2)
+
+	refunds, err := r.GetRefundsByTransactionID(ctx, transaction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, refund := range refunds {
+		if refund.Status != "succeeded" {
+			t.Errorf("refund %s is %q, want succeeded", refund.RefundKey, refund.Status)
+		}
+	}
+}
+
+func TestRepositoryPendingTransactions(t *testing.T) {
+	db := databasetest.Open(t)
+	r := NewRepository(db)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+	stale := createPendingTransaction(t, db, testCampaign.ID, 50000)
+
+	overdue := createPendingTransaction(t, db, testCampaign.ID, 20000)
+	expiresAt := time.Now().Add(-time.Minute)
+	overdue.ExpiresAt = &expiresAt
+	overdue.CreatedAt = time.Now()
+	if _, err := r.Update(ctx, overdue); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := r.GetOpenBefore(ctx, []string{"pending"}, time.Now().Add(-30*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].ID != stale.ID {
+		t.Errorf("GetOpenBefore = %+v, want only transaction %d", pending, stale.ID)
+	}
+
+	expired, err := r.ExpirePendingBefore(ctx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expired != 1 {
+		t.Errorf("ExpirePendingBefore expired %d transactions, want 1", expired)
+	}
+
+	stored, err := r.GetByID(ctx, overdue.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != "expired" {
+		t.Errorf("status of the overdue transaction = %q, want expired", stored.Status)
+	}
+}
+
+func TestRepositorySaveReceipt(t *testing.T) {
+	db := databasetest.Open(t)
+	r := NewRepository(db)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+	first := createPendingTransaction(t, db, testCampaign.ID, 50000)
+	second := createPendingTransaction(t, db, testCampaign.ID, 20000)
+
+	number := func(receipt Receipt) string { return fmt.Sprintf("RCP-%06d", receipt.ID) }
+
+	// A receipt is never visible without its number, so two can be issued side by side
+	for _, transaction := range []Transaction{first, second} {
+		receipt, err := r.SaveReceipt(ctx, Receipt{TransactionID: transaction.ID}, number)
+		if err != nil {
+			t.Fatalf("SaveReceipt of transaction %d: %v", transaction.ID, err)
+		}
+		if receipt.Number != number(receipt) || receipt.FileName != receipt.Number+".pdf" {
+			t.Errorf("receipt = %q in %q, want %q", receipt.Number, receipt.FileName, number(receipt))
+		}
+	}
+
+	// Numbering that fails takes the inserted row with it
+	third := createPendingTransaction(t, db, testCampaign.ID, 10000)
+	taken := func(receipt Receipt) string { return "RCP-000001" }
+	if _, err := r.SaveReceipt(ctx, Receipt{TransactionID: third.ID}, taken); err == nil {
+		t.Fatal("SaveReceipt with a taken number succeeded")
+	}
+
+	receipt, err := r.GetReceiptByTransactionID(ctx, third.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.ID != 0 {
+		t.Errorf("receipt %+v was left behind by the failed numbering", receipt)
+	}
+}
+
+func TestRepositoryUpdateMessageStatusKeepsThePayment(t *testing.T) {
+	db := databasetest.Open(t)
+	r := NewRepository(db)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+	stale := createPendingTransaction(t, db, testCampaign.ID, 50000)
+
+	// The payment settles while the owner is moderating the copy loaded before it
+	settled, err := r.SettlePaid(ctx, stale, openStatuses, nil)
+	if err != nil || !settled {
+		t.Fatalf("SettlePaid = %v, %v; want true", settled, err)
+	}
+
+	stale.MessageStatus = "hidden"
+	if err := r.UpdateMessageStatus(ctx, stale); err != nil {
+		t.Fatalf("UpdateMessageStatus: %v", err)
+	}
+
+	stored, err := r.GetByID(ctx, stale.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != "paid" || stored.MessageStatus != "hidden" {
+		t.Errorf("transaction = %q with message %q, want paid with message hidden", stored.Status, stored.MessageStatus)
+	}
+}
+
+func TestRepositoryUpdateClaimTokenKeepsTheRefund(t *testing.T) {
+	db := databasetest.Open(t)
+	r := NewRepository(db)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+	paid := createPendingTransaction(t, db, testCampaign.ID, 50000)
+
+	settled, err := r.SettlePaid(ctx, paid, openStatuses, nil)
+	if err != nil || !settled {
+		t.Fatalf("SettlePaid = %v, %v; want true", settled, err)
+	}
+	paid.Status = "paid"
+
+	// The receipt goroutine still holds this copy when the transaction is refunded
+	refund, err := r.SaveRefundRequest(ctx, Refund{TransactionID: paid.ID, Status: "pending"}, []string{"paid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if completed, err := r.CompleteRefund(ctx, refund, ledger.RefundJournal(testCampaign.ID, refund.RefundKey, refund.Amount)); err != nil || !completed {
+		t.Fatalf("CompleteRefund = %v, %v; want true", completed, err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	paid.ClaimTokenHash = "token-hash"
+	paid.ClaimTokenExpiresAt = &expiresAt
+	if err := r.UpdateClaimToken(ctx, paid); err != nil {
+		t.Fatalf("UpdateClaimToken: %v", err)
+	}
+
+	stored, err := r.GetByID(ctx, paid.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != "refunded" || stored.RefundedAmount != 50000 || stored.ClaimTokenHash != "token-hash" {
+		t.Errorf("transaction = %q with %d refunded and token %q, want refunded with 50000 and the new token", stored.Status, stored.RefundedAmount, stored.ClaimTokenHash)
+	}
+}
@@ -2,11 +2,23 @@ package transaction
 
 import (
 	"backer/campaign"
+	"backer/config"
+	"backer/ledger"
+	"backer/mailer"
+	"backer/metrics"
 	"backer/payment"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel"
 )
 
 // Custom errors
@@ -14,29 +26,61 @@ var (
 	ErrCampaignNotFound    = errors.New("campaign not found")
 	ErrNotAuthorized       = errors.New("not authorized")
 	ErrTransactionNotFound = errors.New("transaction not found")
-	ErrInvalidSignature    = errors.New("invalid signature")
-	ErrInvalidOrderID      = errors.New("invalid order id")
+	ErrInvalidSignature    = payment.ErrInvalidSignature
+
+	ErrTransactionNotRefundable = errors.New("transaction is not refundable")
+	ErrRefundAmountExceeded     = errors.New("refund amount exceeds refundable amount")
+
+	ErrInvalidClaimToken   = errors.New("invalid or expired claim token")
+	ErrReceiptNotAvailable = errors.New("receipt is only available for paid transactions")
+	ErrInvalidPerk         = errors.New("perk is not offered by this campaign")
+	ErrAmountOutOfRange    = errors.New("donation amount out of range")
+	ErrFraudAlertNotFound  = errors.New("fraud alert not found")
+	ErrFraudAlertResolved  = errors.New("fraud alert is already resolved")
 )
 
 type service struct {
 	repository         Repository
 	campaignRepository campaign.Repository
 	paymentService     payment.Service
+	mailer             mailer.Mailer
+	receipts           sync.WaitGroup
 }
 
+var tracer = otel.Tracer("backer/transaction")
+
 type Service interface {
-	GetTransactionsByCampaignID(input GetCampaignTransactionsInput) ([]Transaction, error)
-	GetTransactionsByUserID(userID int) ([]Transaction, error)
-	CreateTransaction(input CreateTransactionInput) (Transaction, error)
-	ProcessPayment(input TransactionNotificationInput) error
+	GetTransactionsByCampaignID(ctx context.Context, input GetCampaignTransactionsInput) ([]Transaction, error)
+	GetTransactionsByUserID(ctx context.Context, userID int) ([]Transaction, error)
+	CreateTransaction(ctx context.Context, input CreateTransactionInput) (Transaction, error)
+	ProcessNotification(ctx context.Context, provider string, body []byte) error
+	ProcessPayment(ctx context.Context, event payment.Event) error
+	Reconcile(ctx context.Context, staleAfter time.Duration) (ReconcileReport, error)
+	ExpireOverdueTransactions(ctx context.Context) (int64, error)
+	RefundTransaction(ctx context.Context, inputID GetTransactionInput, inputData CreateRefundInput) (Refund, error)
+	ChargeRecurring(ctx context.Context, input ChargeRecurringInput) (Transaction, error)
+	GetSupportersWall(ctx context.Context, input GetSupportersWallInput) ([]Transaction, error)
+	GetCampaignBackers(ctx context.Context, input GetCampaignBackersInput) ([]Transaction, int64, error)
+	GetLeaderboard(ctx context.Context, input GetLeaderboardInput) ([]BackerTotal, error)
+	ModerateMessage(ctx context.Context, inputID GetTransactionInput, inputData ModerateMessageInput) (Transaction, error)
+	CreateGuestTransaction(ctx context.Context, input CreateGuestTransactionInput) (Transaction, error)
+	ClaimGuestTransactions(ctx context.Context, input ClaimGuestTransactionsInput) (int64, error)
+	GetReceipt(ctx context.Context, input GetReceiptInput) (Receipt, []byte, error)
+	ExportCampaignTransactions(ctx context.Context, input ExportCampaignTransactionsInput) (Export, error)
+	GetFraudAlerts(ctx context.Context, input GetFraudAlertsInput) ([]FraudAlert, error)
+	ResolveFraudAlert(ctx context.Context, inputID GetFraudAlertInput, inputData ResolveFraudAlertInput) (FraudAlert, error)
+	WaitForReceipts(ctx context.Context) bool
 }
 
-func NewService(repository Repository, campaignRepository campaign.Repository, paymentService payment.Service) *service {
-	return &service{repository, campaignRepository, paymentService}
+func NewService(repository Repository, campaignRepository campaign.Repository, paymentService payment.Service, mailer mailer.Mailer) *service {
+	return &service{repository: repository, campaignRepository: campaignRepository, paymentService: paymentService, mailer: mailer}
 }
 
-func (s *service) GetTransactionsByCampaignID(input GetCampaignTransactionsInput) ([]Transaction, error) {
-	campaign, err := s.campaignRepository.FindByID(input.ID)
+func (s *service) GetTransactionsByCampaignID(ctx context.Context, input GetCampaignTransactionsInput) ([]Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.GetTransactionsByCampaignID")
+	defer span.End()
+
+	campaign, err := s.campaignRepository.FindByID(ctx, input.ID)
 	if err != nil {
 		return []Transaction{}, err
 	}
@@ -49,7 +93,7 @@ func (s *service) GetTransactionsByCampaignID(input GetCampaignTransactionsInput
 		return []Transaction{}, ErrNotAuthorized
 	}
 
-	transactions, err := s.repository.GetByCampaignID(input.ID)
+	transactions, err := s.repository.GetByCampaignID(ctx, input.ID)
 	if err != nil {
 		return transactions, err
 	}
@@ -57,8 +101,11 @@ func (s *service) GetTransactionsByCampaignID(input GetCampaignTransactionsInput
 	return transactions, nil
 }
 
-func (s *service) GetTransactionsByUserID(userID int) ([]Transaction, error) {
-	transactions, err := s.repository.GetByUserID(userID)
+func (s *service) GetTransactionsByUserID(ctx context.Context, userID int) ([]Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.GetTransactionsByUserID")
+	defer span.End()
+
+	transactions, err := s.repository.GetByUserID(ctx, userID)
 	if err != nil {
 		return transactions, err
 	}
@@ -66,8 +113,31 @@ func (s *service) GetTransactionsByUserID(userID int) ([]Transaction, error) {
 	return transactions, nil
 }
 
-func (s *service) CreateTransaction(input CreateTransactionInput) (Transaction, error) {
-	campaign, err := s.campaignRepository.FindByID(input.CampaignID)
+func (s *service) CreateTransaction(ctx context.Context, input CreateTransactionInput) (Transaction, error) {
+	ctx, span := tracer.Start(ctx, "transaction.CreateTransaction")
+	defer span.End()
+
+	transaction := Transaction{}
+	transaction.CampaignID = input.CampaignID
+	transaction.Amount = input.Amount
+	transaction.UserID = input.User.ID
+	transaction.Perk = strings.TrimSpace(input.Perk)
+	transaction.IsAnonymous = input.IsAnonymous
+	transaction.Message = strings.TrimSpace(input.Message)
+	transaction.MessageStatus = moderateMessage(transaction.Message)
+
+	customer := payment.Customer{
+		Name:  input.User.Name,
+		Email: input.User.Email,
+	}
+
+	return s.startCheckout(ctx, transaction, customer)
+}
+
+// startCheckout saves a new pending transaction and opens the provider's checkout
+// for it.
+func (s *service) startCheckout(ctx context.Context, transaction Transaction, customer payment.Customer) (Transaction, error) {
+	campaign, err := s.campaignRepository.FindByID(ctx, transaction.CampaignID)
 	if err != nil {
 		return Transaction{}, err
 	}
@@ -76,32 +146,55 @@ func (s *service) CreateTransaction(input CreateTransactionInput) (Transaction,
 		return Transaction{}, ErrCampaignNotFound
 	}
 
-	transaction := Transaction{}
-	transaction.CampaignID = input.CampaignID
-	transaction.Amount = input.Amount
-	transaction.UserID = input.User.ID
+	if err := CheckAmount(campaign, transaction.Amount); err != nil {
+		return Transaction{}, err
+	}
+
+	if transaction.Perk != "" && !hasPerk(campaign, transaction.Perk) {
+		return Transaction{}, ErrInvalidPerk
+	}
+
 	transaction.Status = "pending"
 
-	timestamp := time.Now().Format("20060102150405")
-	transaction.Code = fmt.Sprintf("TRX-%s-%d-%d", timestamp, input.User.ID, input.CampaignID)
+	// Campaigns may be tied to a specific gateway, otherwise the configured default is used
+	transaction.Provider = campaign.PaymentProvider
+	if transaction.Provider == "" {
+		transaction.Provider = s.paymentService.DefaultProvider()
+	}
+
+	now := time.Now()
+	if config.AppConfig.PaymentExpiry > 0 {
+		expiresAt := now.Add(config.AppConfig.PaymentExpiry)
+		transaction.ExpiresAt = &expiresAt
+	}
+
+	transaction.Code, err = generateCode()
+	if err != nil {
+		return transaction, err
+	}
 
-	newTransaction, err := s.repository.Save(transaction)
+	newTransaction, err := s.repository.Save(ctx, transaction)
 	if err != nil {
 		return newTransaction, err
 	}
+	metrics.TransactionCreated(newTransaction.Provider)
 
 	paymentTransaction := payment.Transaction{
-		ID:     newTransaction.ID,
-		Amount: newTransaction.Amount,
+		OrderID:     orderIDOf(newTransaction),
+		Amount:      newTransaction.Amount,
+		Currency:    "IDR",
+		Description: campaign.Name,
+		CreatedAt:   newTransaction.CreatedAt,
+		ExpiresAt:   newTransaction.ExpiresAt,
 	}
 
-	paymentURL, err := s.paymentService.GetPaymentURL(paymentTransaction, input.User)
+	checkout, err := s.paymentService.CreateCheckout(ctx, newTransaction.Provider, paymentTransaction, customer)
 	if err != nil {
 		return newTransaction, err
 	}
 
-	newTransaction.PaymentURL = paymentURL
-	newTransaction, err = s.repository.Update(newTransaction)
+	newTransaction.PaymentURL = checkout.URL
+	newTransaction, err = s.repository.Update(ctx, newTransaction)
 	if err != nil {
 		return newTransaction, err
 	}
@@ -109,65 +202,222 @@ func (s *service) CreateTransaction(input CreateTransactionInput) (Transaction,
 	return newTransaction, nil
 }
 
-func (s *service) ProcessPayment(input TransactionNotificationInput) error {
-	transactionID, err := strconv.Atoi(input.OrderID)
+// ProcessNotification verifies and applies a notification sent by the given payment
+// provider. An empty provider means the default one.
+func (s *service) ProcessNotification(ctx context.Context, provider string, body []byte) error {
+	ctx, span := tracer.Start(ctx, "transaction.ProcessNotification")
+	defer span.End()
+
+	event, err := s.paymentService.ParseNotification(provider, body)
 	if err != nil {
-		return fmt.Errorf("%w: order_id=%q", ErrInvalidOrderID, input.OrderID)
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			if provider == "" {
+				provider = s.paymentService.DefaultProvider()
+			}
+			metrics.SignatureFailure(provider)
+		}
+		return err
 	}
 
-	transaction, err := s.repository.GetByID(transactionID)
+	return s.ProcessPayment(ctx, event)
+}
+
+func (s *service) ProcessPayment(ctx context.Context, event payment.Event) error {
+	ctx, span := tracer.Start(ctx, "transaction.ProcessPayment")
+	defer span.End()
+
+	transaction, err := s.findByOrderID(ctx, event.OrderID)
 	if err != nil {
 		return err
 	}
 
-	if transaction.ID == 0 {
-		return ErrTransactionNotFound
+	// An order can only be settled by the gateway it was created with
+	if transaction.Provider != "" && transaction.Provider != event.Provider {
+		return fmt.Errorf("%w: order_id=%q belongs to provider %q", ErrTransactionNotFound, event.OrderID, transaction.Provider)
 	}
 
-	if !s.paymentService.VerifySignature(input.OrderID, input.StatusCode, input.GrossAmount, input.SignatureKey) {
-		return ErrInvalidSignature
+	if event.Status == payment.StatusRefunded || event.Status == payment.StatusPartiallyRefunded {
+		_, err = s.applyRefundNotification(ctx, transaction, event.Refunds)
+		return err
 	}
 
-	if transaction.Status == "paid" || transaction.Status == "cancelled" {
-		return nil
-	}
+	_, err = s.applyEvent(ctx, transaction, event)
+	return err
+}
 
-	switch input.TransactionStatus {
-	case "capture":
-		if input.FraudStatus == "accept" {
-			transaction.Status = "paid"
+// openStatuses are the statuses a payment event can still move a transaction out of.
+// A transaction we expired locally is not final: if the backer still managed to
+// pay, the provider's settlement wins.
+var openStatuses = []string{"pending", "expired"}
+
+// applyEvent applies a payment event from the provider to a transaction.
+// Notifications and reconciliation both go through it, so a payment is only ever
+// credited after the same checks.
+func (s *service) applyEvent(ctx context.Context, transaction Transaction, event payment.Event) (Transaction, error) {
+	// Never credit a campaign with a payment that disagrees with what the backer was charged for
+	if event.Status == payment.StatusPaid {
+		alerts := checkNotificationIntegrity(transaction, event)
+		if len(alerts) > 0 {
+			return s.holdForReview(ctx, transaction, alerts)
 		}
-	case "settlement":
-		transaction.Status = "paid"
-	case "deny", "expire", "cancel":
-		transaction.Status = "cancelled"
 	}
 
-	updatedTransaction, err := s.repository.Update(transaction)
-	if err != nil {
-		return err
+	return s.applyPaymentStatus(ctx, transaction, event.Status)
+}
+
+// applyPaymentStatus moves an open transaction according to the provider's payment
+// status. One held for review only moves by an admin. When another caller moved the
+// transaction first, it is returned unchanged.
+func (s *service) applyPaymentStatus(ctx context.Context, transaction Transaction, paymentStatus string) (Transaction, error) {
+	if transaction.Status != "pending" && transaction.Status != "expired" {
+		return transaction, nil
 	}
 
-	if updatedTransaction.Status == "paid" {
-		if err := s.applyCampaignProgress(updatedTransaction); err != nil {
-			return err
-		}
+	switch paymentStatus {
+	case payment.StatusPaid:
+		return s.settlePaid(ctx, transaction, openStatuses)
+	case payment.StatusFailed, payment.StatusExpired:
+		return s.cancel(ctx, transaction, openStatuses)
+	}
+
+	return transaction, nil
+}
+
+// settlePaid marks a transaction paid, credits its campaign and books it in the
+// ledger in one database transaction, then sends the receipt in the background. If
+// any of it fails nothing is saved, so the provider's retry of the notification
+// settles it again. Only the caller that moves the transaction out of one of the
+// from statuses settles it.
+func (s *service) settlePaid(ctx context.Context, transaction Transaction, from []string) (Transaction, error) {
+	paidAt := time.Now()
+	paidTransaction := transaction
+	paidTransaction.Status = "paid"
+	paidTransaction.PaidAt = &paidAt
+
+	journals := ledger.DonationJournals(paidTransaction.CampaignID, paidTransaction.Code, paidTransaction.Amount)
+
+	settled, err := s.repository.SettlePaid(ctx, paidTransaction, from, journals)
+	if err != nil || !settled {
+		return transaction, err
+	}
+
+	metrics.TransactionPaid(paidTransaction.Provider, paidTransaction.Amount)
+
+	// The payment is recorded, the receipt goes out even if the request is gone. It is
+	// sent in the background so a slow mail server never holds up the notification.
+	s.receipts.Go(func() {
+		s.sendReceipt(context.WithoutCancel(ctx), paidTransaction)
+	})
+
+	return paidTransaction, nil
+}
+
+// cancel marks a transaction cancelled if it is still in one of the from statuses.
+func (s *service) cancel(ctx context.Context, transaction Transaction, from []string) (Transaction, error) {
+	cancelledTransaction := transaction
+	cancelledTransaction.Status = "cancelled"
+
+	cancelled, err := s.repository.UpdateStatus(ctx, cancelledTransaction, from)
+	if err != nil || !cancelled {
+		return transaction, err
+	}
+
+	metrics.TransactionCancelled(cancelledTransaction.Provider)
+
+	return cancelledTransaction, nil
+}
+
+// CheckAmount validates a donation amount against the global limits and the
+// campaign's own minimum.
+func CheckAmount(campaign campaign.Campaign, amount int) error {
+	minimum := config.AppConfig.MinDonationAmount
+	if campaign.MinimumDonation > minimum {
+		minimum = campaign.MinimumDonation
+	}
+
+	if amount < minimum {
+		return fmt.Errorf("%w: amount must be at least %d", ErrAmountOutOfRange, minimum)
+	}
+
+	if amount > config.AppConfig.MaxDonationAmount {
+		return fmt.Errorf("%w: amount must be at most %d", ErrAmountOutOfRange, config.AppConfig.MaxDonationAmount)
 	}
 
 	return nil
 }
 
-// applyCampaignProgress separates the campaign update logic so that
-// ProcessPayment stays simpler (reducing the cognitive complexity flagged by SonarQube).
-func (s *service) applyCampaignProgress(updatedTransaction Transaction) error {
-	campaign, err := s.campaignRepository.FindByID(updatedTransaction.CampaignID)
+// hasPerk tells whether perk is one of the campaign's comma separated perks.
+func hasPerk(campaign campaign.Campaign, perk string) bool {
+	for _, campaignPerk := range strings.Split(campaign.Perks, ",") {
+		if strings.EqualFold(strings.TrimSpace(campaignPerk), perk) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExpireOverdueTransactions marks pending transactions whose payment window has
+// passed as expired, returning how many were affected.
+func (s *service) ExpireOverdueTransactions(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "transaction.ExpireOverdueTransactions")
+	defer span.End()
+
+	return s.repository.ExpirePendingBefore(ctx, time.Now())
+}
+
+// findByOrderID looks a transaction up by the reference the provider knows it by.
+func (s *service) findByOrderID(ctx context.Context, orderID string) (Transaction, error) {
+	transaction, err := s.repository.GetByCode(ctx, orderID)
 	if err != nil {
-		return err
+		return transaction, err
 	}
 
-	campaign.BackerCount = campaign.BackerCount + 1
-	campaign.CurrentAmount = campaign.CurrentAmount + updatedTransaction.Amount
+	if transaction.ID != 0 {
+		return transaction, nil
+	}
 
-	_, err = s.campaignRepository.Update(campaign)
-	return err
+	// Orders created before the switch to codes still settle under their numeric ID
+	transactionID, err := strconv.Atoi(orderID)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("%w: order_id=%q", ErrTransactionNotFound, orderID)
+	}
+
+	transaction, err = s.repository.GetByID(ctx, transactionID)
+	if err != nil {
+		return transaction, err
+	}
+
+	if transaction.ID == 0 || orderIDOf(transaction) != orderID {
+		return Transaction{}, fmt.Errorf("%w: order_id=%q", ErrTransactionNotFound, orderID)
+	}
+
+	return transaction, nil
+}
+
+// legacyCodePattern matches the codes of transactions that were sent to the provider
+// under their numeric ID, before the code itself became the order ID.
+var legacyCodePattern = regexp.MustCompile(`^TRX-\d{14}-\d+-\d+$`)
+
+// orderIDOf is the reference a transaction is known by at the payment provider.
+func orderIDOf(transaction Transaction) string {
+	if legacyCodePattern.MatchString(transaction.Code) {
+		return strconv.Itoa(transaction.ID)
+	}
+
+	return transaction.Code
+}
+
+// generateCode returns an unguessable transaction code. It doubles as the order ID
+// at the payment provider, so it must stay unique across environments sharing a
+// merchant account and must not reveal how many transactions we process.
+func generateCode() (string, error) {
+	randomBytes := make([]byte, 12)
+
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return "TRX-" + strings.ToUpper(hex.EncodeToString(randomBytes)), nil
 }
@@ -0,0 +1,107 @@
+package transaction
+
+import (
+	"backer/payment"
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestSettlePaidRollsBackWhenTheLedgerFails(t *testing.T) {
+	s, db, recorder := newTestService(t)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+	transaction := createPendingTransaction(t, db, testCampaign.ID, 50000)
+
+	failLedger := true
+	err := db.Callback().Create().Before("gorm:create").Register("test:fail_ledger", func(tx *gorm.DB) {
+		if failLedger && tx.Statement.Table == "ledger_journals" {
+			tx.AddError(errors.New("ledger unavailable"))
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.applyPaymentStatus(ctx, transaction, payment.StatusPaid); err == nil {
+		t.Fatal("applyPaymentStatus succeeded although the ledger failed")
+	}
+
+	stored, err := s.repository.GetByID(ctx, transaction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != "pending" {
+		t.Errorf("status after the failed settlement = %q, want pending", stored.Status)
+	}
+	assertCampaignProgress(t, db, testCampaign.ID, 0, 0)
+	assertJournalCount(t, db, 0)
+
+	// The provider retries the notification once the ledger is back
+	failLedger = false
+
+	settled, err := s.applyPaymentStatus(ctx, stored, payment.StatusPaid)
+	if err != nil {
+		t.Fatalf("applyPaymentStatus: %v", err)
+	}
+	if settled.Status != "paid" || settled.PaidAt == nil {
+		t.Errorf("status after the retry = %q paid at %v, want paid with the time", settled.Status, settled.PaidAt)
+	}
+	assertCampaignProgress(t, db, testCampaign.ID, 50000, 1)
+	assertJournalCount(t, db, 2)
+
+	s.WaitForReceipts(ctx)
+	if sent := recorder.sent(); len(sent) != 1 {
+		t.Errorf("sent %d receipts, want 1", len(sent))
+	}
+}
+
+func TestHoldForReviewOnlyHoldsOpenTransactionsOnce(t *testing.T) {
+	s, db, _ := newTestService(t)
+	ctx := context.Background()
+
+	testCampaign := createCampaign(t, db)
+	underpaid := createPendingTransaction(t, db, testCampaign.ID, 50000)
+	cancelled := createPendingTransaction(t, db, testCampaign.ID, 50000)
+
+	cancelled.Status = "cancelled"
+	if _, err := s.repository.UpdateStatus(ctx, cancelled, openStatuses); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, transaction := range []Transaction{underpaid, cancelled} {
+		event := payment.Event{OrderID: transaction.Code, Status: payment.StatusPaid, GrossAmount: "3000.00"}
+
+		// The provider delivers the same notification twice
+		for range 2 {
+			if _, err := s.applyEvent(ctx, transaction, event); err != nil {
+				t.Fatalf("applyEvent: %v", err)
+			}
+		}
+	}
+
+	wantStatuses := map[int]string{underpaid.ID: "review", cancelled.ID: "cancelled"}
+	wantAlerts := map[int]int{underpaid.ID: 1, cancelled.ID: 0}
+	for id, want := range wantStatuses {
+		stored, err := s.repository.GetByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Status != want {
+			t.Errorf("status of transaction %d = %q, want %q", id, stored.Status, want)
+		}
+
+		alerts, err := s.repository.GetOpenFraudAlertsByTransactionID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(alerts) != wantAlerts[id] {
+			t.Errorf("transaction %d has %d fraud alerts, want %d", id, len(alerts), wantAlerts[id])
+		}
+	}
+
+	assertCampaignProgress(t, db, testCampaign.ID, 0, 0)
+}
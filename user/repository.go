@@ -1,12 +1,16 @@
 package user
 
-import "gorm.io/gorm"
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
 
 type Repository interface {
-	Save(user User) (User, error)
-	FindByEmail(email string) (User, error)
-	FindByID(ID int) (User, error)
-	Update(user User) (User, error)
+	Save(ctx context.Context, user User) (User, error)
+	FindByEmail(ctx context.Context, email string) (User, error)
+	FindByID(ctx context.Context, ID int) (User, error)
+	Update(ctx context.Context, user User) (User, error)
 }
 
 type repository struct {
@@ -17,8 +21,8 @@ func NewRepository(db *gorm.DB) *repository {
 	return &repository{db}
 }
 
-func (r *repository) Save(user User) (User, error) {
-	err := r.db.Create(&user).Error
+func (r *repository) Save(ctx context.Context, user User) (User, error) {
+	err := r.db.WithContext(ctx).Create(&user).Error
 	if err != nil {
 		return user, err
 	}
@@ -26,10 +30,11 @@ func (r *repository) Save(user User) (User, error) {
 	return user, nil
 }
 
-func (r *repository) FindByEmail(email string) (User, error) {
+func (r *repository) FindByEmail(ctx context.Context, email string) (User, error) {
 	var user User
 
-	err := r.db.Where("email = ?", email).Find(&user).Error
+	// MySQL compares case-insensitively, Postgres and SQLite need LOWER for the same result
+	err := r.db.WithContext(ctx).Where("LOWER(email) = LOWER(?)", email).Find(&user).Error
 	if err != nil {
 		return user, err
 	}
@@ -37,10 +42,10 @@ func (r *repository) FindByEmail(email string) (User, error) {
 	return user, nil
 }
 
-func (r *repository) FindByID(ID int) (User, error) {
+func (r *repository) FindByID(ctx context.Context, ID int) (User, error) {
 	var user User
 
-	err := r.db.Where("id = ?", ID).Find(&user).Error
+	err := r.db.WithContext(ctx).Where("id = ?", ID).Find(&user).Error
 	if err != nil {
 		return user, err
 	}
@@ -48,8 +53,8 @@ func (r *repository) FindByID(ID int) (User, error) {
 	return user, nil
 }
 
-func (r *repository) Update(user User) (User, error) {
-	err := r.db.Save(&user).Error
+func (r *repository) Update(ctx context.Context, user User) (User, error) {
+	err := r.db.WithContext(ctx).Save(&user).Error
 
 	if err != nil {
 		return user, err
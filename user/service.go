@@ -1,8 +1,10 @@
 package user
 
 import (
+	"context"
 	"errors"
 
+	"go.opentelemetry.io/otel"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -13,12 +15,14 @@ var (
 	ErrInvalidCredentials     = errors.New("invalid email or password")
 )
 
+var tracer = otel.Tracer("backer/user")
+
 type Service interface {
-	RegisterUser(input RegisterUserInput) (User, error)
-	Login(input LoginInput) (User, error)
-	IsEmailAvailable(input CheckEmailInput) (bool, error)
-	SaveAvatar(ID int, fileLocation string) (User, error)
-	GetUserByID(ID int) (User, error)
+	RegisterUser(ctx context.Context, input RegisterUserInput) (User, error)
+	Login(ctx context.Context, input LoginInput) (User, error)
+	IsEmailAvailable(ctx context.Context, input CheckEmailInput) (bool, error)
+	SaveAvatar(ctx context.Context, ID int, fileLocation string) (User, error)
+	GetUserByID(ctx context.Context, ID int) (User, error)
 }
 
 type service struct {
@@ -29,10 +33,13 @@ func NewService(repository Repository) *service {
 	return &service{repository}
 }
 
-func (s *service) RegisterUser(input RegisterUserInput) (User, error) {
+func (s *service) RegisterUser(ctx context.Context, input RegisterUserInput) (User, error) {
+	ctx, span := tracer.Start(ctx, "user.RegisterUser")
+	defer span.End()
+
 	user := User{}
 
-	existingUser, err := s.repository.FindByEmail(input.Email)
+	existingUser, err := s.repository.FindByEmail(ctx, input.Email)
 	if err != nil {
 		return user, err
 	}
@@ -53,7 +60,7 @@ func (s *service) RegisterUser(input RegisterUserInput) (User, error) {
 	user.PasswordHash = string(passwordHash)
 	user.Role = "user"
 
-	newUser, err := s.repository.Save(user)
+	newUser, err := s.repository.Save(ctx, user)
 	if err != nil {
 		return newUser, err
 	}
@@ -61,11 +68,14 @@ func (s *service) RegisterUser(input RegisterUserInput) (User, error) {
 	return newUser, nil
 }
 
-func (s *service) Login(input LoginInput) (User, error) {
+func (s *service) Login(ctx context.Context, input LoginInput) (User, error) {
+	ctx, span := tracer.Start(ctx, "user.Login")
+	defer span.End()
+
 	email := input.Email
 	password := input.Password
 
-	user, err := s.repository.FindByEmail(email)
+	user, err := s.repository.FindByEmail(ctx, email)
 	if err != nil {
 		return user, err
 	}
@@ -82,10 +92,13 @@ func (s *service) Login(input LoginInput) (User, error) {
 	return user, nil
 }
 
-func (s *service) IsEmailAvailable(input CheckEmailInput) (bool, error) {
+func (s *service) IsEmailAvailable(ctx context.Context, input CheckEmailInput) (bool, error) {
+	ctx, span := tracer.Start(ctx, "user.IsEmailAvailable")
+	defer span.End()
+
 	email := input.Email
 
-	user, err := s.repository.FindByEmail(email)
+	user, err := s.repository.FindByEmail(ctx, email)
 	if err != nil {
 		return false, err
 	}
@@ -97,15 +110,18 @@ func (s *service) IsEmailAvailable(input CheckEmailInput) (bool, error) {
 	return false, nil
 }
 
-func (s *service) SaveAvatar(ID int, fileLocation string) (User, error) {
-	user, err := s.repository.FindByID(ID)
+func (s *service) SaveAvatar(ctx context.Context, ID int, fileLocation string) (User, error) {
+	ctx, span := tracer.Start(ctx, "user.SaveAvatar")
+	defer span.End()
+
+	user, err := s.repository.FindByID(ctx, ID)
 	if err != nil {
 		return user, err
 	}
 
 	user.AvatarFileName = fileLocation
 
-	updateUser, err := s.repository.Update(user)
+	updateUser, err := s.repository.Update(ctx, user)
 	if err != nil {
 		return updateUser, err
 	}
@@ -113,8 +129,11 @@ func (s *service) SaveAvatar(ID int, fileLocation string) (User, error) {
 	return updateUser, nil
 }
 
-func (s *service) GetUserByID(ID int) (User, error) {
-	user, err := s.repository.FindByID(ID)
+func (s *service) GetUserByID(ctx context.Context, ID int) (User, error) {
+	ctx, span := tracer.Start(ctx, "user.GetUserByID")
+	defer span.End()
+
+	user, err := s.repository.FindByID(ctx, ID)
 	if err != nil {
 		return user, err
 	}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"backer/config"
+	"backer/helper"
+	"backer/pledge"
+	"backer/transaction"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// runWorker runs job every interval in its own goroutine. wg is done once ctx is
+// cancelled and the job in progress, if any, has finished. A job is not cut short
+// by the cancellation but may run for at most one interval.
+func runWorker(ctx context.Context, wg *sync.WaitGroup, interval time.Duration, job func(ctx context.Context)) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		helper.RunEvery(ctx, interval, func() {
+			jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), interval)
+			defer cancel()
+
+			job(jobCtx)
+		})
+	}()
+}
+
+// startReconcileWorker periodically reconciles stale pending transactions in the background.
+func startReconcileWorker(ctx context.Context, wg *sync.WaitGroup, transactionService transaction.Service) {
+	interval := config.AppConfig.ReconcileInterval
+	if interval <= 0 {
+		slog.Info("Reconciliation worker disabled")
+		return
+	}
+
+	runWorker(ctx, wg, interval, func(ctx context.Context) {
+		report, err := transactionService.Reconcile(ctx, config.AppConfig.ReconcileStaleAfter)
+		if err != nil {
+			slog.Error("Reconciliation failed", "error", err)
+			return
+		}
+
+		slog.Info("Reconciliation done", "checked", report.Checked, "updated", report.Updated,
+			"unknown", report.MissingAtProvider, "discrepancies", len(report.Discrepancies))
+
+		for _, d := range report.Discrepancies {
+			slog.Warn("Reconciliation discrepancy", "transaction_id", d.TransactionID, "order_id", d.OrderID, "kind", d.Kind,
+				"local_status", d.LocalStatus, "provider_status", d.ProviderStatus, "detail", d.Detail)
+		}
+	})
+}
+
+// startExpirySweeper periodically marks pending transactions past their payment window as expired.
+func startExpirySweeper(ctx context.Context, wg *sync.WaitGroup, transactionService transaction.Service) {
+	interval := config.AppConfig.ExpirySweepInterval
+	if interval <= 0 {
+		slog.Info("Expiry sweeper disabled")
+		return
+	}
+
+	runWorker(ctx, wg, interval, func(ctx context.Context) {
+		expired, err := transactionService.ExpireOverdueTransactions(ctx)
+		if err != nil {
+			slog.Error("Expiry sweep failed", "error", err)
+			return
+		}
+
+		if expired > 0 {
+			slog.Info("Expired overdue pending transactions", "count", expired)
+		}
+	})
+}
+
+// startPledgeBilling periodically charges recurring pledges that are due, including dunning retries.
+func startPledgeBilling(ctx context.Context, wg *sync.WaitGroup, pledgeService pledge.Service) {
+	interval := config.AppConfig.PledgeBillingInterval
+	if interval <= 0 {
+		slog.Info("Pledge billing disabled")
+		return
+	}
+
+	runWorker(ctx, wg, interval, func(ctx context.Context) {
+		charged, err := pledgeService.ChargeDuePledges(ctx)
+		if err != nil {
+			slog.Error("Pledge billing failed", "error", err)
+			return
+		}
+
+		if charged > 0 {
+			slog.Info("Charged recurring pledges", "count", charged)
+		}
+	})
+}